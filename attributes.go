@@ -0,0 +1,119 @@
+package cache
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go.opencensus.io/trace"
+)
+
+// RawKey attaches keys to spans unchanged
+func RawKey(k string) string {
+	return k
+}
+
+// TruncatedKey attaches at most the first n bytes of keys to spans, cut back to the start of a UTF-8 character.
+// A negative n attaches nothing.
+func TruncatedKey(n int) func(k string) string {
+	if n < 0 {
+		n = 0
+	}
+	return func(k string) string {
+		if len(k) <= n {
+			return k
+		}
+		i := n
+		for i > 0 && !utf8.RuneStart(k[i]) {
+			i--
+		}
+		return k[:i]
+	}
+}
+
+// HashedKey attaches an HMAC-SHA256 of keys under secret to spans, allowing calls on the same key to be correlated
+// without revealing it. The secret keeps low-entropy keys from being recovered by hashing guesses, it should be
+// random and kept out of the traces.
+func HashedKey(secret []byte) func(k string) string {
+	secret = append([]byte(nil), secret...)
+	return func(k string) string {
+		mac := hmac.New(sha256.New, secret)
+		mac.Write([]byte(k))
+		return hex.EncodeToString(mac.Sum(nil))
+	}
+}
+
+// KeyPrefix attaches the part of keys before the first sep to spans, keys without sep are attached whole
+func KeyPrefix(sep string) func(k string) string {
+	return func(k string) string {
+		if i := strings.Index(k, sep); i >= 0 {
+			return k[:i]
+		}
+		return k
+	}
+}
+
+// keyAttributes returns the span attributes describing k according to options
+func keyAttributes(k string, options TraceOptions) []trace.Attribute {
+	if k == "" || options.KeyAttribute == nil {
+		return nil
+	}
+	return []trace.Attribute{trace.StringAttribute("cache.key", options.KeyAttribute(k))}
+}
+
+// valueAttributes returns the span attributes describing x according to options
+func valueAttributes(x interface{}, options TraceOptions) []trace.Attribute {
+	if x == nil || !options.ValueAttributes {
+		return nil
+	}
+	sizer := options.Sizer
+	if sizer == nil {
+		sizer = estimateSize
+	}
+	return []trace.Attribute{
+		trace.StringAttribute("cache.value.type", fmt.Sprintf("%T", x)),
+		trace.Int64Attribute("cache.value.size", sizer(x)),
+	}
+}
+
+// batchAttributes returns the span attributes describing a batch call on n keys
+func batchAttributes(n int) []trace.Attribute {
+	if n == 0 {
+		return nil
+	}
+	return []trace.Attribute{trace.Int64Attribute("cache.keys", int64(n))}
+}
+
+// lookupAttributes returns the span attributes counting the hits and misses of a batch call
+func lookupAttributes(res Result) []trace.Attribute {
+	if res.Hits == 0 && res.Misses == 0 {
+		return nil
+	}
+	return []trace.Attribute{
+		trace.Int64Attribute("cache.hits", int64(res.Hits)),
+		trace.Int64Attribute("cache.misses", int64(res.Misses)),
+	}
+}
+
+// scanAttributes returns the span attributes counting the keys examined and removed by a scan
+func scanAttributes(res Result) []trace.Attribute {
+	if res.Examined == 0 && res.Removed == 0 {
+		return nil
+	}
+	return []trace.Attribute{
+		trace.Int64Attribute("cache.keys_examined", int64(res.Examined)),
+		trace.Int64Attribute("cache.keys_removed", int64(res.Removed)),
+	}
+}
+
+// expirationAttributes returns the span attributes describing when the item stored by a call expires
+func expirationAttributes(res Result) []trace.Attribute {
+	if res.Expiration.IsZero() {
+		return nil
+	}
+	return []trace.Attribute{trace.StringAttribute("cache.expiration", res.Expiration.Format(time.RFC3339Nano))}
+}
@@ -0,0 +1,104 @@
+package cache
+
+import (
+	"context"
+	"testing"
+
+	pgocache "github.com/patrickmn/go-cache"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestKeyAttributePolicies(t *testing.T) {
+	for name, tt := range map[string]struct {
+		f    func(string) string
+		want string
+	}{
+		"raw":       {RawKey, "user:42:profile"},
+		"truncated": {TruncatedKey(7), "user:42"},
+		"short":     {TruncatedKey(64), "user:42:profile"},
+		"negative":  {TruncatedKey(-1), ""},
+		"hashed":    {HashedKey([]byte("secret")), "3f8dd56fef319412b87539cd052158c3ead51f9adabd66ba2a8e1776aea0188f"},
+		"prefix":    {KeyPrefix(":"), "user"},
+		"noprefix":  {KeyPrefix("/"), "user:42:profile"},
+	} {
+		if got := tt.f("user:42:profile"); got != tt.want {
+			t.Errorf("%s: expected %q, got %q", name, tt.want, got)
+		}
+	}
+}
+
+func TestTruncatedKeyRunes(t *testing.T) {
+	for n, want := range map[int]string{0: "", 1: "", 2: "é", 3: "éa", 4: "éa", 5: "éa", 6: "éa€"} {
+		if got := TruncatedKey(n)("éa€"); got != want {
+			t.Errorf("%d: expected %q, got %q", n, want, got)
+		}
+	}
+}
+
+func TestHashedKeySecret(t *testing.T) {
+	if HashedKey([]byte("a"))("user:42") == HashedKey([]byte("b"))("user:42") {
+		t.Error("expected keys hashed under different secrets to differ")
+	}
+}
+
+func TestKeyAttributes(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	tc := Wrap(pgocache.New(pgocache.DefaultExpiration, 0),
+		WithAllTraceOptions(),
+		WithOpenTelemetry(tp, nil),
+		WithKeyAttribute(KeyPrefix(":")),
+		WithValueAttributes(true),
+	)
+
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "parent")
+	tc.Set(ctx, "user:42", "alice", pgocache.DefaultExpiration)
+	tc.Get(ctx, "user:42")
+	tc.ItemCount(ctx)
+	parent.End()
+
+	for _, span := range recorder.Ended() {
+		attrs := map[attribute.Key]attribute.Value{}
+		for _, attr := range span.Attributes() {
+			attrs[attr.Key] = attr.Value
+		}
+		switch span.Name() {
+		case "go.cache.set", "go.cache.get":
+			if key := attrs["cache.key"].AsString(); key != "user" {
+				t.Errorf("%s: expected redacted cache.key attribute, got %q", span.Name(), key)
+			}
+			if typ := attrs["cache.value.type"].AsString(); typ != "string" {
+				t.Errorf("%s: expected cache.value.type attribute, got %q", span.Name(), typ)
+			}
+			if size := attrs["cache.value.size"].AsInt64(); size <= 0 {
+				t.Errorf("%s: expected cache.value.size attribute, got %d", span.Name(), size)
+			}
+		case "go.cache.itemcount":
+			if _, ok := attrs["cache.key"]; ok {
+				t.Error("unexpected cache.key attribute on unkeyed call")
+			}
+		}
+	}
+}
+
+func TestKeyAttributesDisabledByDefault(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	tc := Wrap(pgocache.New(pgocache.DefaultExpiration, 0), WithAllTraceOptions(), WithOpenTelemetry(tp, nil))
+
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "parent")
+	tc.Set(ctx, "secret", "value", pgocache.DefaultExpiration)
+	parent.End()
+
+	for _, span := range recorder.Ended() {
+		for _, attr := range span.Attributes() {
+			if attr.Key == "cache.key" || attr.Key == "cache.value.type" {
+				t.Errorf("%s: unexpected %s attribute", span.Name(), attr.Key)
+			}
+		}
+	}
+}
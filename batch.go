@@ -0,0 +1,64 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// GetMulti returns the items stored under keys, misses are read through the Store as Get does and keys still
+// missing are left out of the result.
+// The call is recorded as a single span and latency measurement with the hits and misses counted as lookups.
+func (w *Wrapper) GetMulti(ctx context.Context, keys []string) (items map[string]interface{}) {
+	var hits int
+	ctx, end := w.startBatchOp(ctx, "go.cache.getmulti", w.options.GetMulti, len(keys))
+	defer func() {
+		end(BatchResult(hits, len(keys)-hits))
+	}()
+
+	items = make(map[string]interface{}, len(keys))
+	for _, k := range keys {
+		key := w.key(k)
+		v, _, found := w.get(ctx, "go.cache.getmulti", key)
+		if !found && !IsAbsent(v) && w.readThrough(ctx, key) {
+			v, _, found = w.get(ctx, "go.cache.getmulti", key)
+		}
+		if found {
+			items[k] = v
+			hits++
+		}
+	}
+
+	return
+}
+
+// SetMulti stores items with the expiration d, replacing any existing items.
+// The call is recorded as a single span and latency measurement, along with the first error of the Store.
+func (w *Wrapper) SetMulti(ctx context.Context, items map[string]interface{}, d time.Duration) {
+	var err error
+	ctx, end := w.startBatchOp(ctx, "go.cache.setmulti", w.options.SetMulti, len(items))
+	defer func() {
+		end(writeResult(err))
+	}()
+
+	for k, x := range items {
+		if serr := w.set(ctx, w.key(k), x, w.jitter(d)); err == nil {
+			err = serr
+		}
+	}
+}
+
+// DeleteMulti deletes the items stored under keys.
+// The call is recorded as a single span and latency measurement, along with the first error of the Store.
+func (w *Wrapper) DeleteMulti(ctx context.Context, keys []string) {
+	var err error
+	ctx, end := w.startBatchOp(ctx, "go.cache.deletemulti", w.options.DeleteMulti, len(keys))
+	defer func() {
+		end(writeResult(err))
+	}()
+
+	for _, k := range keys {
+		if _, rerr := w.remove(ctx, w.key(k), EvictionReasonDeleted); err == nil {
+			err = rerr
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package cache
+
+import (
+	"context"
+	"testing"
+
+	pgocache "github.com/patrickmn/go-cache"
+	"go.opencensus.io/stats/view"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestBatch(t *testing.T) {
+	tc := Wrap(pgocache.New(pgocache.DefaultExpiration, 0), WithAllTraceOptions())
+
+	tc.SetMulti(context.Background(), map[string]interface{}{"a": 1, "b": 2, "c": 3}, pgocache.DefaultExpiration)
+	items := tc.GetMulti(context.Background(), []string{"a", "b", "d"})
+	if len(items) != 2 || items["a"] != 1 || items["b"] != 2 {
+		t.Error("unexpected items:", items)
+	}
+
+	tc.DeleteMulti(context.Background(), []string{"a", "c"})
+	if n := tc.ItemCount(context.Background()); n != 1 {
+		t.Error("expected 1 item after DeleteMulti, got:", n)
+	}
+}
+
+func TestBatchSpan(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	tc := Wrap(pgocache.New(pgocache.DefaultExpiration, 0), WithAllTraceOptions(), WithOpenTelemetry(tp, nil))
+
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "parent")
+	tc.SetMulti(ctx, map[string]interface{}{"a": 1, "b": 2}, pgocache.DefaultExpiration)
+	tc.GetMulti(ctx, []string{"a", "b", "c"})
+	parent.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 3 {
+		t.Fatal("expected a single span per batch call, got:", len(spans))
+	}
+	for _, span := range spans {
+		if span.Name() != "go.cache.getmulti" {
+			continue
+		}
+		attrs := map[attribute.Key]int64{}
+		for _, attr := range span.Attributes() {
+			attrs[attr.Key] = attr.Value.AsInt64()
+		}
+		if attrs["cache.keys"] != 3 || attrs["cache.hits"] != 2 || attrs["cache.misses"] != 1 {
+			t.Error("unexpected batch attributes:", span.Attributes())
+		}
+		return
+	}
+	t.Error("no span recorded for go.cache.getmulti")
+}
+
+func TestBatchLookups(t *testing.T) {
+	if err := view.Register(GoCacheLookupsView, GoCacheInstanceCallsView); err != nil {
+		t.Fatal("Error registering views:", err)
+	}
+	defer view.Unregister(GoCacheLookupsView, GoCacheInstanceCallsView)
+
+	tc := Wrap(pgocache.New(pgocache.DefaultExpiration, 0), WithInstanceName("batch-lookups"))
+	tc.Set(context.Background(), "a", 1, pgocache.DefaultExpiration)
+	tc.GetMulti(context.Background(), []string{"a", "b", "c"})
+
+	rows, err := view.RetrieveData(GoCacheLookupsView.Name)
+	if err != nil {
+		t.Fatal("Error retrieving view data:", err)
+	}
+	lookups := map[string]float64{}
+	for _, row := range rows {
+		var name, method, status string
+		for _, tag := range row.Tags {
+			switch tag.Key {
+			case GoCacheName:
+				name = tag.Value
+			case GoCacheMethod:
+				method = tag.Value
+			case GoCacheStatus:
+				status = tag.Value
+			}
+		}
+		if name == "batch-lookups" && method == "go.cache.getmulti" {
+			lookups[status] += row.Data.(*view.SumData).Value
+		}
+	}
+	if lookups[StatusFound] != 1 || lookups[StatusNotFound] != 2 {
+		t.Error("expected 1 hit and 2 misses, got:", lookups)
+	}
+
+	rows, err = view.RetrieveData(GoCacheInstanceCallsView.Name)
+	if err != nil {
+		t.Fatal("Error retrieving view data:", err)
+	}
+	var calls int64
+	for _, row := range rows {
+		for _, tag := range row.Tags {
+			if tag.Key == GoCacheMethod && tag.Value == "go.cache.getmulti" {
+				calls += row.Data.(*view.CountData).Value
+			}
+		}
+	}
+	if calls != 1 {
+		t.Error("expected a single call to be recorded, got:", calls)
+	}
+}
@@ -1,4 +1,4 @@
-// Package cache instruments patrickmn/go-cache interactions with Open Census
+// Package cache instruments patrickmn/go-cache interactions with Open Census or OpenTelemetry
 package cache
 
 import (
@@ -29,8 +29,11 @@ type Cacher interface {
 	DecrementUintptr(c context.Context, k string, n uintptr) (uintptr, error)
 	Delete(c context.Context, k string)
 	DeleteExpired(c context.Context)
+	DeleteMulti(c context.Context, keys []string)
 	Flush(c context.Context)
 	Get(c context.Context, k string) (interface{}, bool)
+	GetMulti(c context.Context, keys []string) map[string]interface{}
+	GetOrLoad(c context.Context, k string, loader LoaderFunc) (interface{}, error)
 	GetWithExpiration(c context.Context, k string) (interface{}, time.Time, bool)
 	Increment(c context.Context, k string, n int64) error
 	IncrementFloat(c context.Context, k string, n float64) error
@@ -57,4 +60,7 @@ type Cacher interface {
 	SaveFile(c context.Context, fname string) error
 	Set(c context.Context, k string, x interface{}, d time.Duration)
 	SetDefault(c context.Context, k string, x interface{})
+	SetMulti(c context.Context, items map[string]interface{}, d time.Duration)
+	SetSliding(c context.Context, k string, x interface{}, d time.Duration)
+	Touch(c context.Context, k string, d time.Duration) error
 }
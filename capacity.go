@@ -0,0 +1,133 @@
+package cache
+
+import (
+	"context"
+	"sync"
+)
+
+// capacity keeps a Wrapper within its configured item and byte limits
+type capacity struct {
+	mu       sync.Mutex
+	maxItems int
+	maxBytes int64
+
+	// sizer is nil when there is no byte limit, items then count as zero bytes
+	sizer  func(interface{}) int64
+	policy EvictionPolicy
+	sizes  map[string]int64
+	bytes  int64
+}
+
+// newCapacity returns nil when no limits are configured
+func newCapacity(o TraceOptions) *capacity {
+	if o.MaxItems <= 0 && o.MaxBytes <= 0 {
+		return nil
+	}
+	c := &capacity{
+		maxItems: o.MaxItems,
+		maxBytes: o.MaxBytes,
+		sizes:    make(map[string]int64),
+	}
+	// items are only sized when there is a byte limit to keep them within
+	if c.maxBytes > 0 {
+		if c.sizer = o.Sizer; c.sizer == nil {
+			c.sizer = estimateSize
+		}
+	}
+	if o.EvictionPolicy != nil {
+		c.policy = o.EvictionPolicy()
+	} else {
+		c.policy = NewLRUPolicy()
+	}
+	return c
+}
+
+// added records that k was stored with x and returns the keys that must be evicted to stay within capacity.
+// count is the number of items in the cache, including k.
+func (c *capacity) added(k string, x interface{}, count int) (victims []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var size int64
+	if c.sizer != nil {
+		size = c.sizer(x)
+	}
+	if old, ok := c.sizes[k]; ok {
+		c.bytes -= old
+		c.policy.Removed(k)
+	}
+
+	for c.over(count-len(victims), c.bytes+size) {
+		victim, ok := c.policy.Victim()
+		if !ok {
+			break
+		}
+		c.bytes -= c.sizes[victim]
+		delete(c.sizes, victim)
+		victims = append(victims, victim)
+	}
+
+	c.policy.Added(k)
+	c.sizes[k] = size
+	c.bytes += size
+
+	return victims
+}
+
+func (c *capacity) over(count int, bytes int64) bool {
+	return (c.maxItems > 0 && count > c.maxItems) || (c.maxBytes > 0 && bytes > c.maxBytes)
+}
+
+func (c *capacity) accessed(k string) {
+	c.mu.Lock()
+	c.policy.Accessed(k)
+	c.mu.Unlock()
+}
+
+func (c *capacity) removed(k string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if size, ok := c.sizes[k]; ok {
+		c.bytes -= size
+		delete(c.sizes, k)
+		c.policy.Removed(k)
+	}
+}
+
+// sizing reports whether c tracks the size of the items in the cache
+func (c *capacity) sizing() bool {
+	return c != nil && c.sizer != nil
+}
+
+func (c *capacity) size() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.bytes
+}
+
+func (c *capacity) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for k := range c.sizes {
+		c.policy.Removed(k)
+	}
+	c.sizes = make(map[string]int64)
+	c.bytes = 0
+}
+
+// admit records that k was stored with x, evicting items as needed to keep the cache within capacity
+func (w *Wrapper) admit(ctx context.Context, k string, x interface{}) {
+	if w.capacity == nil {
+		return
+	}
+	for _, victim := range w.capacity.added(k, x, w.count()) {
+		w.delete(ctx, victim, EvictionReasonCapacity)
+	}
+}
+
+// accessed records a read of k with the eviction policy
+func (w *Wrapper) accessed(k string) {
+	if w.capacity != nil {
+		w.capacity.accessed(k)
+	}
+}
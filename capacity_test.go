@@ -0,0 +1,131 @@
+package cache
+
+import (
+	"context"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"unsafe"
+
+	pgocache "github.com/patrickmn/go-cache"
+)
+
+func TestMaxItemsLRU(t *testing.T) {
+	tc := Wrap(pgocache.New(pgocache.DefaultExpiration, 0), WithMaxItems(2))
+
+	var evicted []string
+	tc.OnEvicted(context.Background(), func(k string, v interface{}) {
+		evicted = append(evicted, k)
+	})
+
+	tc.Set(context.Background(), "a", 1, pgocache.DefaultExpiration)
+	tc.Set(context.Background(), "b", 2, pgocache.DefaultExpiration)
+	tc.Get(context.Background(), "a")
+	tc.Set(context.Background(), "c", 3, pgocache.DefaultExpiration)
+
+	if n := tc.ItemCount(context.Background()); n != 2 {
+		t.Error("expected 2 items, got:", n)
+	}
+	if _, found := tc.Get(context.Background(), "b"); found {
+		t.Error("least recently used item b was not evicted")
+	}
+	if _, found := tc.Get(context.Background(), "a"); !found {
+		t.Error("recently used item a was evicted")
+	}
+	if len(evicted) != 1 || evicted[0] != "b" {
+		t.Error("expected OnEvicted to be called for b, got:", evicted)
+	}
+}
+
+func TestMaxItemsLFU(t *testing.T) {
+	tc := Wrap(pgocache.New(pgocache.DefaultExpiration, 0), WithMaxItems(2), WithEvictionPolicy(NewLFUPolicy))
+
+	tc.Set(context.Background(), "a", 1, pgocache.DefaultExpiration)
+	tc.Set(context.Background(), "b", 2, pgocache.DefaultExpiration)
+	tc.Get(context.Background(), "a")
+	tc.Get(context.Background(), "a")
+	tc.Get(context.Background(), "b")
+	tc.Set(context.Background(), "c", 3, pgocache.DefaultExpiration)
+
+	if _, found := tc.Get(context.Background(), "b"); found {
+		t.Error("least frequently used item b was not evicted")
+	}
+	if _, found := tc.Get(context.Background(), "c"); !found {
+		t.Error("newly added item c was evicted")
+	}
+}
+
+func TestMaxItemsRandom(t *testing.T) {
+	tc := Wrap(pgocache.New(pgocache.DefaultExpiration, 0), WithMaxItems(10), WithEvictionPolicy(NewRandomPolicy))
+
+	for i := 0; i < 100; i++ {
+		tc.Set(context.Background(), strconv.Itoa(i), i, pgocache.DefaultExpiration)
+	}
+
+	if n := tc.ItemCount(context.Background()); n != 10 {
+		t.Error("expected 10 items, got:", n)
+	}
+	if _, found := tc.Get(context.Background(), "99"); !found {
+		t.Error("most recently added item was evicted")
+	}
+}
+
+func TestMaxBytes(t *testing.T) {
+	tc := Wrap(pgocache.New(pgocache.DefaultExpiration, 0),
+		WithMaxBytes(100),
+		WithSizer(func(x interface{}) int64 {
+			return int64(len(x.(string)))
+		}),
+	)
+
+	tc.Set(context.Background(), "a", string(make([]byte, 40)), pgocache.DefaultExpiration)
+	tc.Set(context.Background(), "b", string(make([]byte, 40)), pgocache.DefaultExpiration)
+	tc.Set(context.Background(), "c", string(make([]byte, 40)), pgocache.DefaultExpiration)
+
+	if _, found := tc.Get(context.Background(), "a"); found {
+		t.Error("item a was not evicted when over MaxBytes")
+	}
+	if n := tc.ItemCount(context.Background()); n != 2 {
+		t.Error("expected 2 items, got:", n)
+	}
+
+	tc.Delete(context.Background(), "b")
+	tc.Set(context.Background(), "d", string(make([]byte, 40)), pgocache.DefaultExpiration)
+	if n := tc.ItemCount(context.Background()); n != 2 {
+		t.Error("deleted item was not released from MaxBytes, items:", n)
+	}
+}
+
+func TestMaxItemsSkipsSizing(t *testing.T) {
+	var calls int32
+	tc := Wrap(pgocache.New(pgocache.DefaultExpiration, 0),
+		WithMaxItems(10),
+		WithSizer(func(x interface{}) int64 {
+			atomic.AddInt32(&calls, 1)
+			return 1
+		}),
+	)
+
+	tc.Set(context.Background(), "a", 1, pgocache.DefaultExpiration)
+	if n := atomic.LoadInt32(&calls); n != 0 {
+		t.Error("expected items not to be sized without MaxBytes, sizer calls:", n)
+	}
+}
+
+func TestEstimateSizeFlatSlices(t *testing.T) {
+	type payload struct {
+		ID    int64
+		Body  []byte
+		Marks []float64
+		Pairs [][2]int32
+	}
+	x := payload{Body: make([]byte, 1<<20), Marks: make([]float64, 10), Pairs: make([][2]int32, 4)}
+
+	want := int64(unsafe.Sizeof(x)) + 1<<20 + 10*8 + 4*8
+	if got := estimateSize(x); got != want {
+		t.Errorf("expected %d bytes, got %d", want, got)
+	}
+	if got := estimateSize(&x); got != want+int64(unsafe.Sizeof(&x)) {
+		t.Errorf("expected %d bytes through a pointer, got %d", want+int64(unsafe.Sizeof(&x)), got)
+	}
+}
@@ -0,0 +1,224 @@
+package cache
+
+import (
+	"context"
+	"sync"
+)
+
+// EvictionReason describes why an item left the cache
+type EvictionReason string
+
+// The following reasons are reported for evicted items
+const (
+	// EvictionReasonExpired is reported for items removed after their expiration
+	EvictionReasonExpired EvictionReason = "EXPIRED"
+
+	// EvictionReasonDeleted is reported for items removed by Delete
+	EvictionReasonDeleted EvictionReason = "DELETED"
+
+	// EvictionReasonCapacity is reported for items removed to keep the cache within its configured capacity
+	EvictionReasonCapacity EvictionReason = "CAPACITY"
+
+	// EvictionReasonReplaced is reported for items overwritten by Set, SetDefault or Replace
+	EvictionReasonReplaced EvictionReason = "REPLACED"
+
+	// EvictionReasonFlushed is reported for items removed by Flush
+	EvictionReasonFlushed EvictionReason = "FLUSHED"
+
+	// EvictionReasonInvalidated is reported for items removed by InvalidateTag
+	EvictionReasonInvalidated EvictionReason = "INVALIDATED"
+)
+
+// EvictionFunc is called with the reason whenever an item leaves the cache
+type EvictionFunc func(ctx context.Context, k string, v interface{}, reason EvictionReason)
+
+// pendingEviction holds the reason and context of a removal started by a Wrapper
+type pendingEviction struct {
+	ctx    context.Context
+	reason EvictionReason
+
+	// by is the Wrapper, or namespace of it, that started the removal
+	by *Wrapper
+}
+
+// ListenerID identifies an eviction listener registered with AddEvictionListener
+type ListenerID uint64
+
+type listener struct {
+	id ListenerID
+	f  EvictionFunc
+}
+
+// evictions tracks the eviction listeners and the removals in progress so that the
+// callback go-cache invokes can be attributed to a reason
+type evictions struct {
+	mu        sync.Mutex
+	nextID    ListenerID
+	listeners []listener
+	pending   map[string]pendingEviction
+}
+
+// listenerSlots hold the listeners of a Wrapper set through OnEvicted and OnEvictedWithReason, which replace
+// each other. They are guarded by the mutex of the evictions they were added to.
+type listenerSlots struct {
+	onEvicted           ListenerID
+	onEvictedWithReason ListenerID
+}
+
+func newEvictions() *evictions {
+	return &evictions{
+		pending: make(map[string]pendingEviction),
+	}
+}
+
+func (e *evictions) add(f EvictionFunc) ListenerID {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.addLocked(f)
+}
+
+func (e *evictions) addLocked(f EvictionFunc) ListenerID {
+	e.nextID++
+	// copy on write so notifications can iterate over a snapshot without holding the lock
+	listeners := make([]listener, len(e.listeners), len(e.listeners)+1)
+	copy(listeners, e.listeners)
+	e.listeners = append(listeners, listener{id: e.nextID, f: f})
+	return e.nextID
+}
+
+func (e *evictions) remove(id ListenerID) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.removeLocked(id)
+}
+
+func (e *evictions) removeLocked(id ListenerID) {
+	listeners := make([]listener, 0, len(e.listeners))
+	for _, l := range e.listeners {
+		if l.id != id {
+			listeners = append(listeners, l)
+		}
+	}
+	e.listeners = listeners
+}
+
+// replace swaps the listener held in slot for f, a nil f only removes it
+func (e *evictions) replace(slot *ListenerID, f EvictionFunc) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if *slot != 0 {
+		e.removeLocked(*slot)
+		*slot = 0
+	}
+	if f != nil {
+		*slot = e.addLocked(f)
+	}
+}
+
+// legacyListener adapts a go-cache eviction callback, returning nil for a nil f
+func legacyListener(f func(string, interface{})) EvictionFunc {
+	if f == nil {
+		return nil
+	}
+	return func(ctx context.Context, k string, v interface{}, reason EvictionReason) {
+		// go-cache never reported replaced or flushed items to its callback
+		if reason != EvictionReasonReplaced && reason != EvictionReasonFlushed {
+			f(k, v)
+		}
+	}
+}
+
+func (e *evictions) snapshot() []listener {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.listeners
+}
+
+func (e *evictions) begin(k string, p pendingEviction) {
+	e.mu.Lock()
+	e.pending[k] = p
+	e.mu.Unlock()
+}
+
+// end clears the pending removal of k, reporting whether go-cache took it by evicting an item
+func (e *evictions) end(k string) (taken bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	_, pending := e.pending[k]
+	delete(e.pending, k)
+	return !pending
+}
+
+// take returns and clears the pending removal of k. Removals not started by the Wrapper are
+// made by go-cache itself when deleting expired items.
+func (e *evictions) take(k string) pendingEviction {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	p, ok := e.pending[k]
+	if !ok {
+		return pendingEviction{ctx: context.Background(), reason: EvictionReasonExpired}
+	}
+	delete(e.pending, k)
+	return p
+}
+
+// delete removes k from the underlying cache, attributing the eviction to reason, and reports whether an item was removed
+func (w *Wrapper) delete(ctx context.Context, k string, reason EvictionReason) (removed bool) {
+	w.evictions.begin(k, pendingEviction{ctx: ctx, reason: reason, by: w})
+	w.Cache.Delete(k)
+	return w.evictions.end(k)
+}
+
+// evicted is registered with go-cache and is called for every item it removes
+func (w *Wrapper) evicted(k string, v interface{}) {
+	p := w.evictions.take(k)
+	if p.by == nil {
+		p.by = w
+	}
+
+	if w.capacity != nil {
+		w.capacity.removed(k)
+	}
+	w.forget(k)
+	w.negatives.removed(k, v)
+
+	p.by.notifyEvicted(p.ctx, k, v, p.reason)
+
+	if w.options.CacheOnEvicted != nil && !IsAbsent(v) {
+		w.options.CacheOnEvicted(k, v)
+	}
+}
+
+// notifyEvicted records the eviction of k and calls every registered listener. Keys known to be absent are not
+// items, their removal is not reported.
+func (w *Wrapper) notifyEvicted(ctx context.Context, k string, v interface{}, reason EvictionReason) {
+	if IsAbsent(v) {
+		return
+	}
+	w.signal(ctx, Signal{Kind: SignalEviction, Reason: reason})
+	w.watchers.evicted(ctx, k, v, reason)
+
+	for _, l := range w.evictions.snapshot() {
+		callListener(ctx, l.f, k, v, reason)
+	}
+}
+
+// callListener calls f, recovering from any panic so that one listener cannot prevent the others from being notified
+func callListener(ctx context.Context, f EvictionFunc, k string, v interface{}, reason EvictionReason) {
+	defer func() {
+		_ = recover()
+	}()
+	f(ctx, k, v, reason)
+}
+
+// forget drops the version and tags of an evicted key unless it has been stored again since
+func (w *Wrapper) forget(k string) {
+	unlock := w.locks.lock(k)
+	defer unlock()
+
+	if _, found := w.Cache.Get(k); !found {
+		w.versions.remove(k)
+		w.tags.remove(k)
+		w.sliding.remove(k)
+	}
+}
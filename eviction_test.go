@@ -0,0 +1,177 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	pgocache "github.com/patrickmn/go-cache"
+	"go.opencensus.io/stats/view"
+)
+
+type evictionRecorder struct {
+	mu      sync.Mutex
+	reasons map[string]EvictionReason
+}
+
+func (r *evictionRecorder) record(ctx context.Context, k string, v interface{}, reason EvictionReason) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reasons[k] = reason
+}
+
+func (r *evictionRecorder) reason(k string) EvictionReason {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.reasons[k]
+}
+
+func TestOnEvictedWithReason(t *testing.T) {
+	tc := Wrap(pgocache.New(pgocache.DefaultExpiration, 0), WithAllTraceOptions(), WithMaxItems(4))
+	r := &evictionRecorder{reasons: make(map[string]EvictionReason)}
+	tc.OnEvictedWithReason(context.Background(), r.record)
+
+	tc.Set(context.Background(), "capacity", 1, pgocache.DefaultExpiration)
+
+	tc.Set(context.Background(), "deleted", 1, pgocache.DefaultExpiration)
+	tc.Delete(context.Background(), "deleted")
+
+	tc.Set(context.Background(), "replaced", 1, pgocache.DefaultExpiration)
+	tc.Set(context.Background(), "replaced", 2, pgocache.DefaultExpiration)
+
+	tc.Set(context.Background(), "expired", 1, time.Millisecond)
+	<-time.After(5 * time.Millisecond)
+	tc.DeleteExpired(context.Background())
+
+	tc.Set(context.Background(), "b", 1, pgocache.DefaultExpiration)
+	tc.Set(context.Background(), "c", 1, pgocache.DefaultExpiration)
+	tc.Set(context.Background(), "d", 1, pgocache.DefaultExpiration)
+
+	tc.Flush(context.Background())
+
+	for k, want := range map[string]EvictionReason{
+		"deleted":  EvictionReasonDeleted,
+		"replaced": EvictionReasonFlushed,
+		"expired":  EvictionReasonExpired,
+		"capacity": EvictionReasonCapacity,
+		"d":        EvictionReasonFlushed,
+	} {
+		if got := r.reason(k); got != want {
+			t.Errorf("expected %s to be evicted with %s, got %s", k, want, got)
+		}
+	}
+}
+
+func TestOnEvictedWithReasonReplaced(t *testing.T) {
+	tc := Wrap(pgocache.New(pgocache.DefaultExpiration, 0))
+
+	var old interface{}
+	tc.OnEvictedWithReason(context.Background(), func(ctx context.Context, k string, v interface{}, reason EvictionReason) {
+		if reason == EvictionReasonReplaced {
+			old = v
+		}
+	})
+
+	tc.Set(context.Background(), "a", 1, pgocache.DefaultExpiration)
+	if err := tc.Replace(context.Background(), "a", 2, pgocache.DefaultExpiration); err != nil {
+		t.Fatal("Error replacing a:", err)
+	}
+	if old != 1 {
+		t.Error("expected the replaced value to be reported, got:", old)
+	}
+}
+
+func TestEvictionsView(t *testing.T) {
+	if err := view.Register(GoCacheEvictionsView); err != nil {
+		t.Fatal("Error registering view:", err)
+	}
+	defer view.Unregister(GoCacheEvictionsView)
+
+	tc := Wrap(pgocache.New(pgocache.DefaultExpiration, 0), WithInstanceName("evictions-view"))
+	tc.Set(context.Background(), "a", 1, pgocache.DefaultExpiration)
+	tc.Delete(context.Background(), "a")
+
+	rows, err := view.RetrieveData(GoCacheEvictionsView.Name)
+	if err != nil {
+		t.Fatal("Error retrieving view data:", err)
+	}
+	for _, row := range rows {
+		var name, reason string
+		for _, tag := range row.Tags {
+			switch tag.Key {
+			case GoCacheName:
+				name = tag.Value
+			case GoCacheEvictionReason:
+				reason = tag.Value
+			}
+		}
+		if name == "evictions-view" && reason == string(EvictionReasonDeleted) {
+			return
+		}
+	}
+	t.Error("no eviction recorded for the deleted item:", rows)
+}
+
+func TestEvictionListeners(t *testing.T) {
+	tc := Wrap(pgocache.New(pgocache.DefaultExpiration, 0))
+
+	var first, second, legacy int
+	tc.AddEvictionListener(context.Background(), func(ctx context.Context, k string, v interface{}, reason EvictionReason) {
+		first++
+		panic("listener failure")
+	})
+	id := tc.AddEvictionListener(context.Background(), func(ctx context.Context, k string, v interface{}, reason EvictionReason) {
+		second++
+	})
+	tc.OnEvicted(context.Background(), func(k string, v interface{}) {
+		legacy++
+	})
+
+	tc.Set(context.Background(), "a", 1, pgocache.DefaultExpiration)
+	tc.Delete(context.Background(), "a")
+
+	if first != 1 || second != 1 || legacy != 1 {
+		t.Errorf("expected every listener to be called once, got %d, %d and %d", first, second, legacy)
+	}
+
+	tc.RemoveEvictionListener(context.Background(), id)
+	tc.OnEvicted(context.Background(), nil)
+
+	tc.Set(context.Background(), "a", 1, pgocache.DefaultExpiration)
+	tc.Delete(context.Background(), "a")
+
+	if first != 2 || second != 1 || legacy != 1 {
+		t.Errorf("removed listeners were called, got %d, %d and %d", first, second, legacy)
+	}
+}
+
+func TestCacheOnEvicted(t *testing.T) {
+	c := pgocache.New(pgocache.DefaultExpiration, 0)
+
+	var evicted []string
+	tc := Wrap(c, WithCacheOnEvicted(func(k string, v interface{}) {
+		evicted = append(evicted, k)
+	}))
+	r := &evictionRecorder{reasons: map[string]EvictionReason{}}
+	tc.AddEvictionListener(context.Background(), r.record)
+
+	tc.Set(context.Background(), "a", 1, pgocache.DefaultExpiration)
+	tc.Delete(context.Background(), "a")
+	c.Set("b", 1, time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	c.DeleteExpired()
+
+	if len(evicted) != 2 || evicted[0] != "a" || evicted[1] != "b" {
+		t.Error("expected the callback registered before Wrap to be chained, got:", evicted)
+	}
+	if r.reason("a") != EvictionReasonDeleted || r.reason("b") != EvictionReasonExpired {
+		t.Error("expected the Wrapper to still report evictions, got:", r.reasons)
+	}
+}
+
+func TestWrapNil(t *testing.T) {
+	if w := Wrap(nil); w.Cache != nil {
+		t.Error("expected a Wrapper without a cache")
+	}
+}
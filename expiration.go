@@ -0,0 +1,80 @@
+package cache
+
+import (
+	"math/rand"
+	"time"
+
+	pgocache "github.com/patrickmn/go-cache"
+)
+
+// expiration returns the duration an item set with d should be stored for in the underlying cache.
+// Items are kept for an extra StaleTTL when stale-while-revalidate is enabled.
+func (w *Wrapper) expiration(d time.Duration) time.Duration {
+	if !w.revalidating() || w.options.StaleTTL <= 0 {
+		return d
+	}
+	if d == pgocache.DefaultExpiration {
+		d = w.options.DefaultExpiration
+	}
+	if d <= 0 {
+		return d
+	}
+	return d + w.options.StaleTTL
+}
+
+// jitter returns d shortened by a random amount of up to the TTLJitter fraction of d or TTLJitterDuration, whichever
+// is larger. Items are shortened rather than extended so they never outlive the duration they were stored with, and
+// items that never expire are left alone.
+func (w *Wrapper) jitter(d time.Duration) time.Duration {
+	if w.options.TTLJitter <= 0 && w.options.TTLJitterDuration <= 0 {
+		return d
+	}
+	if d == pgocache.DefaultExpiration {
+		d = w.options.DefaultExpiration
+	}
+	if d <= 0 {
+		return d
+	}
+
+	spread := time.Duration(float64(d) * w.options.TTLJitter)
+	if w.options.TTLJitterDuration > spread {
+		spread = w.options.TTLJitterDuration
+	}
+	if spread >= d {
+		// keep at least a nanosecond, a zero duration would store the item with the default expiration
+		spread = d - 1
+	}
+	if spread <= 0 {
+		return d
+	}
+	return d - time.Duration(rand.Int63n(int64(spread)+1))
+}
+
+// expiresAt returns when an item stored now for d expires, zero if it never does
+func (w *Wrapper) expiresAt(d time.Duration) time.Time {
+	if d == pgocache.DefaultExpiration {
+		d = w.options.DefaultExpiration
+	}
+	if d <= 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(d)
+}
+
+// remaining returns the duration an item expiring at exp is still cached for, to store it again with the same
+// expiration. ok is false once exp has passed, as go-cache would store the item with a non-positive duration forever.
+func remaining(exp time.Time) (d time.Duration, ok bool) {
+	if exp.IsZero() {
+		return pgocache.NoExpiration, true
+	}
+	d = time.Until(exp)
+	return d, d > 0
+}
+
+// storedResult reports when an item stored for d by a call expires in res, unless the call failed with err
+func (w *Wrapper) storedResult(res Result, err error, d time.Duration) Result {
+	if err != nil {
+		return res
+	}
+	return res.WithExpiration(w.expiresAt(d))
+}
@@ -0,0 +1,105 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pgocache "github.com/patrickmn/go-cache"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestDefaultExpiration(t *testing.T) {
+	tc := Wrap(pgocache.New(time.Minute, 0),
+		WithDefaultExpiration(time.Minute),
+		WithRefreshLoader(func(ctx context.Context, k string) (interface{}, time.Duration, error) {
+			return nil, 0, nil
+		}),
+		WithStaleTTL(time.Hour),
+	)
+	if d := tc.expiration(pgocache.DefaultExpiration); d != time.Minute+time.Hour {
+		t.Error("expected the default expiration to be extended by StaleTTL, got:", d)
+	}
+
+	tc = Wrap(pgocache.New(time.Minute, 0), WithTTLJitter(0.5))
+	if d := tc.jitter(pgocache.DefaultExpiration); d != pgocache.DefaultExpiration {
+		t.Error("expected DefaultExpiration to be left to go-cache without WithDefaultExpiration, got:", d)
+	}
+	if exp := tc.expiresAt(pgocache.DefaultExpiration); !exp.IsZero() {
+		t.Error("expected no expiration without WithDefaultExpiration, got:", exp)
+	}
+}
+
+func TestJitter(t *testing.T) {
+	tc := Wrap(pgocache.New(time.Minute, 0), WithDefaultExpiration(time.Minute), WithTTLJitter(0.1))
+
+	seen := map[time.Duration]bool{}
+	for i := 0; i < 100; i++ {
+		d := tc.jitter(10 * time.Second)
+		if d < 9*time.Second || d > 10*time.Second {
+			t.Fatal("expected the duration to be shortened by up to 10%, got:", d)
+		}
+		seen[d] = true
+	}
+	if len(seen) < 2 {
+		t.Error("expected jittered durations to differ")
+	}
+	if d := tc.jitter(pgocache.DefaultExpiration); d < 54*time.Second || d > time.Minute {
+		t.Error("expected the default expiration to be jittered, got:", d)
+	}
+	if d := tc.jitter(pgocache.NoExpiration); d != pgocache.NoExpiration {
+		t.Error("expected items that never expire to be left alone, got:", d)
+	}
+
+	tc = Wrap(pgocache.New(pgocache.NoExpiration, 0), WithDefaultExpiration(pgocache.NoExpiration), WithTTLJitter(0.01), WithTTLJitterDuration(time.Hour))
+	for i := 0; i < 100; i++ {
+		if d := tc.jitter(time.Second); d <= 0 || d > time.Second {
+			t.Fatal("expected the spread to be capped below the duration, got:", d)
+		}
+	}
+	if d := tc.jitter(pgocache.DefaultExpiration); d != pgocache.NoExpiration {
+		t.Error("expected items that never expire by default to be left alone, got:", d)
+	}
+}
+
+func TestJitterExpirationAttribute(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	tc := Wrap(pgocache.New(time.Minute, 0),
+		WithAllTraceOptions(),
+		WithDefaultExpiration(time.Minute),
+		WithAllowRoot(true),
+		WithOpenTelemetry(tp, nil),
+		WithTTLJitterDuration(10*time.Second),
+	)
+	tc.Set(context.Background(), "a", 1, time.Minute)
+	tc.SetDefault(context.Background(), "b", 1)
+	tc.Add(context.Background(), "a", 2, time.Minute)
+
+	ended := recorder.Ended()
+	if len(ended) != 3 {
+		t.Fatal("expected 3 spans, got:", len(ended))
+	}
+	for _, span := range ended[:2] {
+		var exp time.Time
+		for _, attr := range span.Attributes() {
+			if attr.Key == "cache.expiration" {
+				exp, _ = time.Parse(time.RFC3339Nano, attr.Value.AsString())
+			}
+		}
+		_, want, _ := tc.GetWithExpiration(context.Background(), map[string]string{"go.cache.set": "a", "go.cache.setdefault": "b"}[span.Name()])
+		if diff := want.Sub(exp); diff < -time.Second || diff > time.Second {
+			t.Errorf("%s: expected cache.expiration near %v, got %v", span.Name(), want, exp)
+		}
+		if until := time.Until(exp); until < 50*time.Second-time.Second || until > time.Minute {
+			t.Errorf("%s: expected a jittered expiration, got %v", span.Name(), exp)
+		}
+	}
+	for _, attr := range ended[2].Attributes() {
+		if attr.Key == "cache.expiration" {
+			t.Error("expected no expiration attribute on a failed Add")
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sampler periodically records the size of a cache
+type sampler struct {
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+// startSampler records the item count and estimated size of the cache every interval until it is stopped
+func (w *Wrapper) startSampler(interval time.Duration) *sampler {
+	s := &sampler{
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+
+	go func() {
+		defer close(s.done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				w.sample(context.Background())
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+
+	return s
+}
+
+// close stops the sampler and waits for it to exit
+func (s *sampler) close() {
+	s.once.Do(func() {
+		close(s.stop)
+	})
+	<-s.done
+}
+
+// sample records the current item count and estimated size of the cache
+func (w *Wrapper) sample(ctx context.Context) {
+	var bytes int64
+	if w.capacity.sizing() {
+		bytes = w.capacity.size()
+	} else {
+		sizer := w.options.Sizer
+		if sizer == nil {
+			sizer = estimateSize
+		}
+		for _, item := range w.items() {
+			bytes += sizer(item.Object)
+		}
+	}
+
+	w.signal(ctx, Signal{Kind: SignalItemCount, Value: int64(w.itemCount())})
+	w.signal(ctx, Signal{Kind: SignalBytes, Value: bytes})
+}
+
+// Close releases the resources held by the Wrapper, such as the goroutine sampling gauges, and flushes the writes
+// queued for the Store. The error of the last write behind that could not be made is returned.
+// The underlying cache is left untouched and can still be used.
+func (w *Wrapper) Close(ctx context.Context) error {
+	if w.sampler != nil {
+		w.sampler.close()
+	}
+	// namespaces share the writes behind and instruments of the Wrapper they were created from
+	if w.prefix != "" {
+		return nil
+	}
+	if w.options.instruments != nil {
+		w.options.instruments.close()
+	}
+	if w.writer != nil {
+		return w.writer.close()
+	}
+	return nil
+}
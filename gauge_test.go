@@ -0,0 +1,55 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pgocache "github.com/patrickmn/go-cache"
+	"go.opencensus.io/stats/view"
+)
+
+func TestSampleGauges(t *testing.T) {
+	if err := view.Register(GoCacheItemCountView, GoCacheBytesView); err != nil {
+		t.Fatal("Error registering views:", err)
+	}
+	defer view.Unregister(GoCacheItemCountView, GoCacheBytesView)
+
+	tc := Wrap(pgocache.New(pgocache.DefaultExpiration, 0),
+		WithInstanceName("gauges"),
+		WithSampleInterval(5*time.Millisecond),
+	)
+	tc.Set(context.Background(), "a", "value", pgocache.DefaultExpiration)
+	tc.Set(context.Background(), "b", "value", pgocache.DefaultExpiration)
+
+	<-time.After(20 * time.Millisecond)
+	if err := tc.Close(context.Background()); err != nil {
+		t.Fatal("Error closing wrapper:", err)
+	}
+	if err := tc.Close(context.Background()); err != nil {
+		t.Fatal("Error closing wrapper twice:", err)
+	}
+
+	if items := lastValue(t, GoCacheItemCountView, "gauges"); items != 2 {
+		t.Error("expected an item count of 2, got:", items)
+	}
+	if bytes := lastValue(t, GoCacheBytesView, "gauges"); bytes <= 0 {
+		t.Error("expected a positive size, got:", bytes)
+	}
+}
+
+func lastValue(t *testing.T, v *view.View, instanceName string) float64 {
+	rows, err := view.RetrieveData(v.Name)
+	if err != nil {
+		t.Fatal("Error retrieving view data:", err)
+	}
+	for _, row := range rows {
+		for _, tag := range row.Tags {
+			if tag.Key == GoCacheName && tag.Value == instanceName {
+				return row.Data.(*view.LastValueData).Value
+			}
+		}
+	}
+	t.Fatal("no data recorded for", instanceName, "in", v.Name)
+	return 0
+}
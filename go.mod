@@ -1,8 +1,21 @@
 module github.com/otternq/patrickmn-go-cache
 
-go 1.14
+go 1.20
 
 require (
 	github.com/patrickmn/go-cache v2.1.0+incompatible
-	go.opencensus.io v0.22.3
+	go.opencensus.io v0.24.0
+	go.opentelemetry.io/otel v1.24.0
+	go.opentelemetry.io/otel/metric v1.24.0
+	go.opentelemetry.io/otel/sdk v1.24.0
+	go.opentelemetry.io/otel/sdk/metric v1.24.0
+	go.opentelemetry.io/otel/trace v1.24.0
+	golang.org/x/sync v0.8.0
+)
+
+require (
+	github.com/go-logr/logr v1.4.4 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	github.com/golang/groupcache v0.0.0-20200121045136-8c9f03a8e57e // indirect
+	golang.org/x/sys v0.17.0 // indirect
 )
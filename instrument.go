@@ -0,0 +1,250 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// Operation describes a Wrapper method call being instrumented
+type Operation struct {
+	// Method names the call, e.g. go.cache.get
+	Method string
+
+	// Trace reports whether spans are enabled for the call by its TraceOptions flag
+	Trace bool
+
+	// Key is the key the call operates on, empty for calls not taking a key
+	Key string
+
+	// Value is the value being stored by the call, if any
+	Value interface{}
+
+	// Keys is the number of keys batch calls operate on
+	Keys int
+}
+
+// Result describes the outcome of an Operation
+type Result struct {
+	// Status is the outcome of the call, one of the Status constants
+	Status string
+
+	// Err is the error returned by the call, if any
+	Err error
+
+	// Value is the value returned by the call, if any
+	Value interface{}
+
+	// Hits and Misses count the lookups made by batch calls
+	Hits, Misses int
+
+	// Examined and Removed count the keys looked at and deleted by scans
+	Examined, Removed int
+
+	// Expiration is when the item stored by the call expires, zero if it never does
+	Expiration time.Time
+}
+
+// WithValue returns a copy of the Result with its Value set to v
+func (r Result) WithValue(v interface{}) Result {
+	r.Value = v
+	return r
+}
+
+// WithScan returns a copy of the Result counting examined keys looked at and removed keys deleted by a scan
+func (r Result) WithScan(examined, removed int) Result {
+	r.Examined, r.Removed = examined, removed
+	return r
+}
+
+// WithExpiration returns a copy of the Result reporting that the stored item expires at exp
+func (r Result) WithExpiration(exp time.Time) Result {
+	r.Expiration = exp
+	return r
+}
+
+// CalledResult is the Result of calls that neither look up an item nor return an error
+func CalledResult() Result {
+	return Result{Status: StatusCalled}
+}
+
+// FoundResult is the Result of a lookup
+func FoundResult(found bool) Result {
+	if found {
+		return Result{Status: StatusFound}
+	}
+	return Result{Status: StatusNotFound}
+}
+
+// BatchResult is the Result of batch calls, hits and misses are the number of keys found and missing
+func BatchResult(hits, misses int) Result {
+	return Result{Status: StatusCalled, Hits: hits, Misses: misses}
+}
+
+// ErrorResult is the Result of calls returning an error
+func ErrorResult(err error) Result {
+	if err != nil {
+		return Result{Status: StatusError, Err: err}
+	}
+	return Result{Status: StatusOK}
+}
+
+// EndOpFunc is called with the Result once an Operation completes
+type EndOpFunc func(res Result)
+
+// SignalKind identifies what a Signal reports
+type SignalKind string
+
+// The following kinds of Signal are recorded by a Wrapper
+const (
+	// SignalEviction reports an item leaving the cache for Reason
+	SignalEviction SignalKind = "EVICTION"
+
+	// SignalStaleServe reports a stale item served by Method while it is refreshed
+	SignalStaleServe SignalKind = "STALE_SERVE"
+
+	// SignalRefreshFailure reports a failed background refresh
+	SignalRefreshFailure SignalKind = "REFRESH_FAILURE"
+
+	// SignalItemCount reports the sampled number of items in Value
+	SignalItemCount SignalKind = "ITEM_COUNT"
+
+	// SignalBytes reports the sampled estimated size of the items in Value
+	SignalBytes SignalKind = "BYTES"
+
+	// SignalStoreQueueDepth reports the number of writes queued for the Store in Value
+	SignalStoreQueueDepth SignalKind = "STORE_QUEUE_DEPTH"
+
+	// SignalStoreFailure reports a write to the Store that failed
+	SignalStoreFailure SignalKind = "STORE_FAILURE"
+
+	// SignalWatchDrop reports an event dropped for a slow Watch subscriber
+	SignalWatchDrop SignalKind = "WATCH_DROP"
+)
+
+// Signal describes something that happened to a Wrapper outside of an Operation
+type Signal struct {
+	// Kind is what the Signal reports, one of the SignalKind constants
+	Kind SignalKind
+
+	// Method names the call that served a stale item
+	Method string
+
+	// Reason is why an evicted item left the cache
+	Reason EvictionReason
+
+	// Value is the sampled value of gauges
+	Value int64
+}
+
+// Instrumenter observes the operations performed by a Wrapper
+type Instrumenter interface {
+	// StartOp is called before op runs. The returned context is used for the call and any
+	// work done on its behalf, the returned EndOpFunc is called once op completes.
+	StartOp(ctx context.Context, op Operation) (context.Context, EndOpFunc)
+
+	// Record is called for every Signal of the Wrapper, ctx is that of the call it happened on behalf of if any
+	Record(ctx context.Context, s Signal)
+}
+
+// NewDefaultInstrumenter returns the Instrumenter used by Wrap when none is configured. It creates spans and records
+// stats through OpenCensus, or OpenTelemetry when options has a TracerProvider or MeterProvider.
+func NewDefaultInstrumenter(options TraceOptions) Instrumenter {
+	if options.MeterProvider != nil && options.instruments == nil {
+		// the instrument names are static, so creating them only fails on a broken provider in which
+		// case stats are recorded through OpenCensus
+		options.instruments, _ = newOtelInstruments(options.MeterProvider)
+	}
+	if options.KeyClassifier != nil && options.keyspaces == nil {
+		options.keyspaces = newKeyspaces(options.KeyClassifier, options.MaxKeyspaces)
+	}
+	return &defaultInstrumenter{
+		options: options,
+	}
+}
+
+type defaultInstrumenter struct {
+	options TraceOptions
+}
+
+func (i *defaultInstrumenter) StartOp(ctx context.Context, op Operation) (context.Context, EndOpFunc) {
+	var span *SpanWrapper
+	if AllowTrace(ctx, op.Trace, i.options.AllowRoot) {
+		ctx, span = StartSpan(ctx, op.Method, i.options)
+	}
+	if span != nil {
+		span.addAttributes(keyAttributes(op.Key, i.options)...)
+		span.addAttributes(valueAttributes(op.Value, i.options)...)
+		span.addAttributes(batchAttributes(op.Keys)...)
+	}
+	var statsFunc = recordCallResult(ctx, op.Method, op.Key, i.options)
+
+	return ctx, func(res Result) {
+		if span != nil {
+			span.addAttributes(valueAttributes(res.Value, i.options)...)
+			span.addAttributes(lookupAttributes(res)...)
+			span.addAttributes(scanAttributes(res)...)
+			span.addAttributes(expirationAttributes(res)...)
+			if res.Err != nil || res.Status == StatusOK || res.Status == StatusError {
+				span.EndSpanWithErr(res.Err)
+			} else {
+				span.EndSpan()
+			}
+		}
+		statsFunc(res)
+	}
+}
+
+// Record records s through OpenCensus, or OpenTelemetry when options has a MeterProvider
+func (i *defaultInstrumenter) Record(ctx context.Context, s Signal) {
+	switch s.Kind {
+	case SignalEviction:
+		recordEviction(ctx, s.Reason, i.options)
+	case SignalStaleServe:
+		recordStaleServe(ctx, s.Method, i.options)
+	case SignalRefreshFailure:
+		recordRefreshFailure(ctx, i.options)
+	case SignalItemCount:
+		recordItemCount(ctx, i.options, s.Value)
+	case SignalBytes:
+		recordBytes(ctx, i.options, s.Value)
+	case SignalStoreQueueDepth:
+		recordStoreQueueDepth(ctx, i.options, s.Value)
+	case SignalStoreFailure:
+		recordStoreFailure(ctx, i.options)
+	case SignalWatchDrop:
+		recordWatchDrop(ctx, i.options)
+	}
+}
+
+// NoopInstrumenter returns an Instrumenter that neither creates spans nor records stats
+func NoopInstrumenter() Instrumenter {
+	return noopInstrumenter{}
+}
+
+type noopInstrumenter struct{}
+
+func (noopInstrumenter) StartOp(ctx context.Context, op Operation) (context.Context, EndOpFunc) {
+	return ctx, func(Result) {}
+}
+
+func (noopInstrumenter) Record(ctx context.Context, s Signal) {}
+
+// signal records s with the Instrumenter of the Wrapper
+func (w *Wrapper) signal(ctx context.Context, s Signal) {
+	w.instrumenter.Record(ctx, s)
+}
+
+// startOp starts instrumenting a call to method, trace is the TraceOptions flag for the method
+func (w *Wrapper) startOp(ctx context.Context, method string, trace bool) (context.Context, EndOpFunc) {
+	return w.instrumenter.StartOp(ctx, Operation{Method: method, Trace: trace})
+}
+
+// startBatchOp starts instrumenting a call to method operating on n keys
+func (w *Wrapper) startBatchOp(ctx context.Context, method string, trace bool, n int) (context.Context, EndOpFunc) {
+	return w.instrumenter.StartOp(ctx, Operation{Method: method, Trace: trace, Keys: n})
+}
+
+// startKeyOp starts instrumenting a call to method operating on k, x is the value being stored if any
+func (w *Wrapper) startKeyOp(ctx context.Context, method string, trace bool, k string, x interface{}) (context.Context, EndOpFunc) {
+	return w.instrumenter.StartOp(ctx, Operation{Method: method, Trace: trace, Key: k, Value: x})
+}
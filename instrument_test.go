@@ -0,0 +1,147 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	pgocache "github.com/patrickmn/go-cache"
+	"go.opencensus.io/stats/view"
+)
+
+type recordedOp struct {
+	op  Operation
+	res Result
+}
+
+type recordingInstrumenter struct {
+	mu      sync.Mutex
+	ops     []recordedOp
+	signals []Signal
+}
+
+func (r *recordingInstrumenter) StartOp(ctx context.Context, op Operation) (context.Context, EndOpFunc) {
+	return ctx, func(res Result) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.ops = append(r.ops, recordedOp{op: op, res: res})
+	}
+}
+
+func (r *recordingInstrumenter) Record(ctx context.Context, s Signal) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.signals = append(r.signals, s)
+}
+
+func (r *recordingInstrumenter) recorded() []Signal {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Signal(nil), r.signals...)
+}
+
+func (r *recordingInstrumenter) results() []recordedOp {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]recordedOp(nil), r.ops...)
+}
+
+func TestInstrumenter(t *testing.T) {
+	r := &recordingInstrumenter{}
+	tc := Wrap(pgocache.New(pgocache.DefaultExpiration, 0), WithGet(true), WithInstrumenter(r))
+
+	tc.Set(context.Background(), "a", 1, pgocache.DefaultExpiration)
+	tc.Get(context.Background(), "a")
+	tc.Get(context.Background(), "b")
+	tc.Add(context.Background(), "a", 2, pgocache.DefaultExpiration)
+
+	want := []recordedOp{
+		{op: Operation{Method: "go.cache.set", Key: "a", Value: 1}, res: Result{Status: StatusCalled}},
+		{op: Operation{Method: "go.cache.get", Trace: true, Key: "a"}, res: Result{Status: StatusFound, Value: 1}},
+		{op: Operation{Method: "go.cache.get", Trace: true, Key: "b"}, res: Result{Status: StatusNotFound}},
+		{op: Operation{Method: "go.cache.add", Key: "a", Value: 2}, res: Result{Status: StatusError}},
+	}
+	got := r.results()
+	if len(got) != len(want) {
+		t.Fatal("expected", len(want), "operations, got:", got)
+	}
+	for i := range want {
+		if got[i].op != want[i].op || got[i].res.Status != want[i].res.Status || got[i].res.Value != want[i].res.Value {
+			t.Errorf("operation %d: expected %v, got %v", i, want[i], got[i])
+		}
+	}
+	if got[3].res.Err == nil {
+		t.Error("expected the Add error to be reported")
+	}
+}
+
+func TestNoopInstrumenter(t *testing.T) {
+	tc := Wrap(pgocache.New(pgocache.DefaultExpiration, 0), WithAllTraceOptions(), WithInstrumenter(NoopInstrumenter()))
+
+	tc.Set(context.Background(), "a", 1, pgocache.DefaultExpiration)
+	if x, found := tc.Get(context.Background(), "a"); !found || x.(int) != 1 {
+		t.Error("unexpected result:", x)
+	}
+}
+
+func TestInstrumenterSignals(t *testing.T) {
+	r := &recordingInstrumenter{}
+	store := newMemoryStore()
+	tc := Wrap(pgocache.New(pgocache.DefaultExpiration, 0),
+		WithInstrumenter(r),
+		WithWatchBuffer(1),
+		WithWriteThrough(store),
+	)
+
+	_, cancel := tc.Watch(context.Background(), "a")
+	defer cancel()
+	tc.Set(context.Background(), "a", 1, pgocache.DefaultExpiration)
+	tc.Set(context.Background(), "a", 2, pgocache.DefaultExpiration)
+	store.failures = 1
+	tc.Delete(context.Background(), "b")
+	tc.Set(context.Background(), "b", 1, pgocache.DefaultExpiration)
+	tc.sample(context.Background())
+
+	got := map[SignalKind][]Signal{}
+	for _, s := range r.recorded() {
+		got[s.Kind] = append(got[s.Kind], s)
+	}
+	if s := got[SignalEviction]; len(s) != 1 || s[0].Reason != EvictionReasonReplaced {
+		t.Error("expected the replaced item to be recorded, got:", s)
+	}
+	if s := got[SignalWatchDrop]; len(s) != 1 {
+		t.Error("expected the dropped event to be recorded, got:", s)
+	}
+	if s := got[SignalStoreFailure]; len(s) != 1 {
+		t.Error("expected the failed write to be recorded, got:", s)
+	}
+	if s := got[SignalItemCount]; len(s) != 1 || s[0].Value != 2 {
+		t.Error("expected the item count to be recorded, got:", s)
+	}
+	if s := got[SignalBytes]; len(s) != 1 || s[0].Value <= 0 {
+		t.Error("expected the size to be recorded, got:", s)
+	}
+}
+
+func TestNoopInstrumenterSignals(t *testing.T) {
+	if err := view.Register(GoCacheEvictionsView); err != nil {
+		t.Fatal("Error registering view:", err)
+	}
+	defer view.Unregister(GoCacheEvictionsView)
+
+	tc := Wrap(pgocache.New(pgocache.DefaultExpiration, 0), WithInstanceName("noop-signals"), WithInstrumenter(NoopInstrumenter()))
+	tc.Set(context.Background(), "a", 1, pgocache.DefaultExpiration)
+	tc.Delete(context.Background(), "a")
+
+	rows, err := view.RetrieveData(GoCacheEvictionsView.Name)
+	if err != nil {
+		t.Fatal("Error retrieving view data:", err)
+	}
+	for _, row := range rows {
+		for _, tag := range row.Tags {
+			if tag.Key == GoCacheName && tag.Value == "noop-signals" {
+				t.Error("expected no evictions to be recorded, got:", row)
+			}
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package cache
+
+import "sync"
+
+// KeyspaceOther is the GoCacheKeyspace tag value of keys classified beyond MaxKeyspaces
+const KeyspaceOther = "other"
+
+// DefaultMaxKeyspaces is the number of distinct GoCacheKeyspace tag values used when MaxKeyspaces is not set
+const DefaultMaxKeyspaces = 32
+
+// keyspaces classifies keys into a bounded set of GoCacheKeyspace tag values. The first max distinct values
+// produced by classifier are kept, later ones collapse into KeyspaceOther to bound the cardinality of views.
+type keyspaces struct {
+	classifier func(k string) string
+	max        int
+
+	mu   sync.RWMutex
+	seen map[string]struct{}
+}
+
+func newKeyspaces(classifier func(k string) string, max int) *keyspaces {
+	if max <= 0 {
+		max = DefaultMaxKeyspaces
+	}
+	return &keyspaces{
+		classifier: classifier,
+		max:        max,
+		seen:       make(map[string]struct{}),
+	}
+}
+
+// classify returns the keyspace of k, or an empty string for unkeyed calls and when no classifier is configured
+func (s *keyspaces) classify(k string) string {
+	if s == nil || k == "" {
+		return ""
+	}
+	keyspace := s.classifier(k)
+
+	s.mu.RLock()
+	_, ok := s.seen[keyspace]
+	s.mu.RUnlock()
+	if ok {
+		return keyspace
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.seen[keyspace]; ok {
+		return keyspace
+	}
+	if len(s.seen) >= s.max {
+		return KeyspaceOther
+	}
+	s.seen[keyspace] = struct{}{}
+	return keyspace
+}
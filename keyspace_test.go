@@ -0,0 +1,74 @@
+package cache
+
+import (
+	"context"
+	"testing"
+
+	pgocache "github.com/patrickmn/go-cache"
+	"go.opencensus.io/stats/view"
+)
+
+func TestKeyspaces(t *testing.T) {
+	s := newKeyspaces(KeyPrefix(":"), 2)
+
+	for k, want := range map[string]string{
+		"user:1":    "user",
+		"session:1": "session",
+		"user:2":    "user",
+		"":          "",
+	} {
+		if got := s.classify(k); got != want {
+			t.Errorf("expected %q to be classified as %q, got %q", k, want, got)
+		}
+	}
+	if got := s.classify("flag:1"); got != KeyspaceOther {
+		t.Errorf("expected keyspaces beyond the cap to be classified as %q, got %q", KeyspaceOther, got)
+	}
+	if got := s.classify("session:2"); got != "session" {
+		t.Errorf("expected known keyspaces to be kept past the cap, got %q", got)
+	}
+
+	var unset *keyspaces
+	if got := unset.classify("user:1"); got != "" {
+		t.Error("expected no keyspace without a classifier, got:", got)
+	}
+}
+
+func TestKeyspaceLookupsView(t *testing.T) {
+	if err := view.Register(GoCacheKeyspaceLookupsView); err != nil {
+		t.Fatal("Error registering view:", err)
+	}
+	defer view.Unregister(GoCacheKeyspaceLookupsView)
+
+	tc := Wrap(pgocache.New(pgocache.DefaultExpiration, 0),
+		WithInstanceName("keyspace-view"),
+		WithKeyClassifier(KeyPrefix(":")),
+		WithMaxKeyspaces(1),
+	)
+	tc.Set(context.Background(), "user:1", 1, pgocache.DefaultExpiration)
+	tc.Get(context.Background(), "user:1")
+	tc.Get(context.Background(), "session:1")
+
+	rows, err := view.RetrieveData(GoCacheKeyspaceLookupsView.Name)
+	if err != nil {
+		t.Fatal("Error retrieving view data:", err)
+	}
+	lookups := map[string]float64{}
+	for _, row := range rows {
+		var name, keyspace string
+		for _, tag := range row.Tags {
+			switch tag.Key {
+			case GoCacheName:
+				name = tag.Value
+			case GoCacheKeyspace:
+				keyspace = tag.Value
+			}
+		}
+		if name == "keyspace-view" {
+			lookups[keyspace] += row.Data.(*view.SumData).Value
+		}
+	}
+	if lookups["user"] != 1 || lookups[KeyspaceOther] != 1 {
+		t.Error("expected a lookup in the user keyspace and one collapsed into other, got:", lookups)
+	}
+}
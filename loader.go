@@ -0,0 +1,99 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// LoaderFunc computes the value for a key missing from the cache along with the duration it should be cached for
+type LoaderFunc func(ctx context.Context) (interface{}, time.Duration, error)
+
+// GetOrLoad returns the item stored under k. On a miss the loader is called and its result is stored in the cache.
+// Concurrent misses for the same key are collapsed into a single loader call whose result is shared by all callers.
+// Loader errors are returned to every waiting caller and are not cached, except for ErrAbsent which caches k as known
+// to be absent. ErrAbsent is returned for keys known to be absent.
+// The loader is not cancelled with the caller that started it, a caller whose context is done stops waiting and
+// returns the context error while the load completes for the others.
+func (w *Wrapper) GetOrLoad(ctx context.Context, k string, loader LoaderFunc) (v interface{}, err error) {
+	var res Result
+	ctx, end := w.startKeyOp(ctx, "go.cache.getorload", w.options.GetOrLoad, k, nil)
+	defer func() {
+		end(res)
+	}()
+
+	k = w.key(k)
+	v, found, err := w.getOrLoad(ctx, "go.cache.getorload", k, loader)
+	if res = loadResult(v, found, err); IsAbsent(v) {
+		return nil, ErrAbsent
+	}
+
+	return v, err
+}
+
+// getOrLoad looks up k for method, calling loader on a miss
+func (w *Wrapper) getOrLoad(ctx context.Context, method string, k string, loader LoaderFunc) (v interface{}, found bool, err error) {
+	if v, _, found = w.get(ctx, method, k); found {
+		return
+	}
+	if IsAbsent(v) {
+		return v, false, ErrAbsent
+	}
+
+	// the load is shared by every waiter, so it keeps the span of the caller starting it but not its cancellation,
+	// and each waiter stops waiting once its own context is done
+	ch := w.loads.DoChan(k, func() (interface{}, error) {
+		return w.load(detach(ctx), k, loader)
+	})
+	select {
+	case res := <-ch:
+		v, err = res.Val, res.Err
+	case <-ctx.Done():
+		return nil, false, ctx.Err()
+	}
+	if IsAbsent(v) {
+		return v, false, ErrAbsent
+	}
+
+	return
+}
+
+// load runs the loader for k under a child span and stores a successful result in the cache
+func (w *Wrapper) load(ctx context.Context, k string, loader LoaderFunc) (v interface{}, err error) {
+	// another caller may have populated the key between our miss and this flight starting
+	if v, found := w.Cache.Get(k); found {
+		return v, nil
+	}
+
+	ctx, end := w.startKeyOp(ctx, "go.cache.getorload.loader", w.options.GetOrLoad, k, nil)
+	defer func() {
+		if errors.Is(err, ErrAbsent) {
+			end(FoundResult(false))
+			return
+		}
+		end(ErrorResult(err).WithValue(v))
+	}()
+
+	var d time.Duration
+	if v, d, err = loader(ctx); errors.Is(err, ErrAbsent) {
+		w.fill(ctx, k, Absent{}, w.negativeTTL())
+		return nil, ErrAbsent
+	} else if err != nil {
+		return nil, err
+	}
+
+	w.fill(ctx, k, v, d)
+
+	return
+}
+
+// loadResult is the Result of a GetOrLoad call. Keys known to be absent are negative hits, and a loader reporting
+// ErrAbsent is a miss rather than an error.
+func loadResult(v interface{}, found bool, err error) Result {
+	if IsAbsent(v) || errors.Is(err, ErrAbsent) {
+		return lookupResult(v, false)
+	}
+	res := FoundResult(found).WithValue(v)
+	res.Err = err
+	return res
+}
@@ -0,0 +1,135 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	pgocache "github.com/patrickmn/go-cache"
+)
+
+func TestGetOrLoad(t *testing.T) {
+	tc := Wrap(pgocache.New(pgocache.DefaultExpiration, 0), WithAllTraceOptions())
+
+	var calls int32
+	loader := func(ctx context.Context) (interface{}, time.Duration, error) {
+		atomic.AddInt32(&calls, 1)
+		return "loaded", pgocache.DefaultExpiration, nil
+	}
+
+	for i := 0; i < 2; i++ {
+		v, err := tc.GetOrLoad(context.Background(), "a", loader)
+		if err != nil {
+			t.Fatal("Error loading a:", err)
+		}
+		if v.(string) != "loaded" {
+			t.Error("a is not loaded:", v)
+		}
+	}
+	if calls != 1 {
+		t.Error("loader was called", calls, "times, expected 1")
+	}
+
+	x, found := tc.Get(context.Background(), "a")
+	if !found || x.(string) != "loaded" {
+		t.Error("loaded value was not stored in the cache:", x)
+	}
+}
+
+func TestGetOrLoadCollapsesConcurrentMisses(t *testing.T) {
+	tc := Wrap(pgocache.New(pgocache.DefaultExpiration, 0), WithAllTraceOptions())
+
+	var (
+		calls   int32
+		release = make(chan struct{})
+		wg      sync.WaitGroup
+	)
+	loader := func(ctx context.Context) (interface{}, time.Duration, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return 1, pgocache.DefaultExpiration, nil
+	}
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := tc.GetOrLoad(context.Background(), "a", loader)
+			if err != nil || v.(int) != 1 {
+				t.Error("unexpected result:", v, err)
+			}
+		}()
+	}
+
+	<-time.After(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if calls != 1 {
+		t.Error("loader was called", calls, "times, expected 1")
+	}
+}
+
+func TestGetOrLoadError(t *testing.T) {
+	tc := Wrap(pgocache.New(pgocache.DefaultExpiration, 0), WithAllTraceOptions())
+
+	loadErr := errors.New("backend unavailable")
+	_, err := tc.GetOrLoad(context.Background(), "a", func(ctx context.Context) (interface{}, time.Duration, error) {
+		return nil, 0, loadErr
+	})
+	if err != loadErr {
+		t.Error("expected loader error, got:", err)
+	}
+
+	if _, found := tc.Get(context.Background(), "a"); found {
+		t.Error("failed load was cached")
+	}
+}
+
+func TestGetOrLoadCancelledCaller(t *testing.T) {
+	tc := Wrap(pgocache.New(pgocache.DefaultExpiration, 0), WithAllTraceOptions())
+
+	var (
+		started = make(chan struct{})
+		release = make(chan struct{})
+	)
+	loader := func(ctx context.Context) (interface{}, time.Duration, error) {
+		close(started)
+		select {
+		case <-release:
+			return 1, pgocache.DefaultExpiration, nil
+		case <-ctx.Done():
+			return nil, 0, ctx.Err()
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error, 1)
+	go func() {
+		_, err := tc.GetOrLoad(ctx, "a", loader)
+		first <- err
+	}()
+	<-started
+
+	second := make(chan interface{}, 1)
+	go func() {
+		v, err := tc.GetOrLoad(context.Background(), "a", loader)
+		if err != nil {
+			t.Error("unexpected error for a waiter that was not cancelled:", err)
+		}
+		second <- v
+	}()
+
+	cancel()
+	if err := <-first; !errors.Is(err, context.Canceled) {
+		t.Error("expected the cancelled caller to stop waiting, got:", err)
+	}
+
+	close(release)
+	if v := <-second; v != 1 {
+		t.Error("expected the load to complete for the other waiter, got:", v)
+	}
+}
@@ -0,0 +1,123 @@
+package cache
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	pgocache "github.com/patrickmn/go-cache"
+	"go.opencensus.io/trace"
+)
+
+// NamespaceSeparator separates the name of a namespace from the keys stored in it
+const NamespaceSeparator = ":"
+
+// namespaceEscaper escapes NamespaceSeparator in namespace names, so that the keys of sibling namespaces never collide
+var namespaceEscaper = strings.NewReplacer(`\`, `\\`, NamespaceSeparator, `\`+NamespaceSeparator)
+
+// namespaces holds the namespaces created from a Wrapper so that each name maps to a single namespace
+type namespaces struct {
+	mu sync.Mutex
+	m  map[string]*Wrapper
+}
+
+func newNamespaces() *namespaces {
+	return &namespaces{
+		m: make(map[string]*Wrapper),
+	}
+}
+
+// Namespace returns a view of the cache whose keys are transparently prefixed with name and NamespaceSeparator.
+// Items, ItemCount, Flush and eviction listeners are scoped to the namespace, and its calls are recorded under name
+// as their instance name. The namespace shares the underlying cache, its janitor, capacity and background refreshes
+// with w, while DeleteExpired, Load and Save still apply to the whole cache. Namespaces can be nested, the keys of a
+// namespace are also keys of w and so of the namespaces it is nested in. NamespaceSeparator is escaped with a
+// backslash in name, so that Namespace("a:b") does not share keys with Namespace("a").
+func (w *Wrapper) Namespace(name string) *Wrapper {
+	w.namespaces.mu.Lock()
+	defer w.namespaces.mu.Unlock()
+
+	if ns, ok := w.namespaces.m[name]; ok {
+		return ns
+	}
+
+	o := w.options
+	o.InstanceName = name
+	o.DefaultAttributes = make([]trace.Attribute, 0, len(w.options.DefaultAttributes)+1)
+	for _, attr := range w.options.DefaultAttributes {
+		if attr.Key() != "cache.instance" {
+			o.DefaultAttributes = append(o.DefaultAttributes, attr)
+		}
+	}
+	o.DefaultAttributes = append(o.DefaultAttributes, trace.StringAttribute("cache.instance", name))
+
+	ns := *w
+	ns.options = o
+	ns.prefix = w.prefix + namespaceEscaper.Replace(name) + NamespaceSeparator
+	ns.slots = &listenerSlots{}
+	ns.namespaces = newNamespaces()
+	ns.sampler = nil
+	// a custom Instrumenter is shared, the default one is recreated to record under the namespace name
+	if _, ok := w.instrumenter.(*defaultInstrumenter); ok {
+		ns.instrumenter = NewDefaultInstrumenter(o)
+	}
+
+	w.namespaces.m[name] = &ns
+	return &ns
+}
+
+// key returns the key k is stored under in the underlying cache
+func (w *Wrapper) key(k string) string {
+	return w.prefix + k
+}
+
+// items returns the unexpired items of the Wrapper keyed as they were stored through it, leaving out the keys known
+// to be absent
+func (w *Wrapper) items() map[string]pgocache.Item {
+	items := w.entries()
+	for k, item := range items {
+		if IsAbsent(item.Object) {
+			delete(items, k)
+		}
+	}
+	return items
+}
+
+// entries returns the unexpired entries of the Wrapper keyed as they were stored through it, including the keys
+// known to be absent
+func (w *Wrapper) entries() map[string]pgocache.Item {
+	items := w.Cache.Items()
+	if w.prefix == "" {
+		return items
+	}
+
+	scoped := make(map[string]pgocache.Item)
+	for k, item := range items {
+		if strings.HasPrefix(k, w.prefix) {
+			scoped[k[len(w.prefix):]] = item
+		}
+	}
+	return scoped
+}
+
+// itemCount returns the number of items stored through the Wrapper, including expired items not yet deleted
+// unless the Wrapper is a namespace. Keys known to be absent are not counted.
+func (w *Wrapper) itemCount() int {
+	if w.prefix == "" {
+		return w.count()
+	}
+	return len(w.items())
+}
+
+// scoped limits an eviction listener to the keys of the Wrapper, which it is called with unprefixed
+func (w *Wrapper) scoped(f EvictionFunc) EvictionFunc {
+	if f == nil || w.prefix == "" {
+		return f
+	}
+	prefix := w.prefix
+	return func(ctx context.Context, k string, v interface{}, reason EvictionReason) {
+		if strings.HasPrefix(k, prefix) {
+			f(ctx, k[len(prefix):], v, reason)
+		}
+	}
+}
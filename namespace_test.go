@@ -0,0 +1,116 @@
+package cache
+
+import (
+	"context"
+	"testing"
+
+	pgocache "github.com/patrickmn/go-cache"
+	"go.opencensus.io/stats/view"
+)
+
+func TestNamespace(t *testing.T) {
+	tc := Wrap(pgocache.New(pgocache.DefaultExpiration, 0), WithAllTraceOptions())
+	users := tc.Namespace("users")
+	sessions := tc.Namespace("sessions")
+
+	if tc.Namespace("users") != users {
+		t.Error("expected a single namespace per name")
+	}
+
+	users.Set(context.Background(), "1", "alice", pgocache.DefaultExpiration)
+	sessions.Set(context.Background(), "1", "token", pgocache.DefaultExpiration)
+	tc.Set(context.Background(), "1", "root", pgocache.DefaultExpiration)
+
+	if v, _ := users.Get(context.Background(), "1"); v != "alice" {
+		t.Error("unexpected users item:", v)
+	}
+	if v, _ := tc.Get(context.Background(), "users:1"); v != "alice" {
+		t.Error("expected namespaced keys to be prefixed, got:", v)
+	}
+	if n := users.ItemCount(context.Background()); n != 1 {
+		t.Error("expected 1 item in the namespace, got:", n)
+	}
+	if items := sessions.Items(context.Background()); len(items) != 1 || items["1"].Object != "token" {
+		t.Error("unexpected sessions items:", items)
+	}
+
+	users.Flush(context.Background())
+	if n := tc.ItemCount(context.Background()); n != 2 {
+		t.Error("expected the namespace flush to leave other items, got:", n)
+	}
+}
+
+func TestNamespaceSeparatorInName(t *testing.T) {
+	tc := Wrap(pgocache.New(pgocache.DefaultExpiration, 0))
+	a := tc.Namespace("a")
+	ab := tc.Namespace("a:b")
+
+	a.Set(context.Background(), "b:c", "a", pgocache.DefaultExpiration)
+	ab.Set(context.Background(), "c", "a:b", pgocache.DefaultExpiration)
+
+	if v, _ := a.Get(context.Background(), "b:c"); v != "a" {
+		t.Error("expected namespaces not to share keys, got:", v)
+	}
+	if v, _ := tc.Get(context.Background(), `a\:b:c`); v != "a:b" {
+		t.Error("expected the separator to be escaped in the namespace name, got:", v)
+	}
+	if items := a.Items(context.Background()); len(items) != 1 || items["b:c"].Object != "a" {
+		t.Error("expected the items of another namespace to be left out, got:", items)
+	}
+
+	a.Flush(context.Background())
+	if v, found := ab.Get(context.Background(), "c"); !found || v != "a:b" {
+		t.Error("expected the namespace flush to leave the other namespace, got:", v, found)
+	}
+}
+
+func TestNamespaceEvictionListeners(t *testing.T) {
+	tc := Wrap(pgocache.New(pgocache.DefaultExpiration, 0))
+	users := tc.Namespace("users")
+
+	var root, scoped []string
+	tc.OnEvicted(context.Background(), func(k string, v interface{}) {
+		root = append(root, k)
+	})
+	users.OnEvicted(context.Background(), func(k string, v interface{}) {
+		scoped = append(scoped, k)
+	})
+
+	users.Set(context.Background(), "1", 1, pgocache.DefaultExpiration)
+	tc.Set(context.Background(), "2", 2, pgocache.DefaultExpiration)
+	users.Delete(context.Background(), "1")
+	tc.Delete(context.Background(), "2")
+
+	if len(scoped) != 1 || scoped[0] != "1" {
+		t.Error("expected the namespace listener to see its own keys unprefixed, got:", scoped)
+	}
+	if len(root) != 2 {
+		t.Error("expected the root listener to see every key, got:", root)
+	}
+}
+
+func TestNamespaceInstanceName(t *testing.T) {
+	if err := view.Register(GoCacheInstanceCallsView); err != nil {
+		t.Fatal("Error registering view:", err)
+	}
+	defer view.Unregister(GoCacheInstanceCallsView)
+
+	tc := Wrap(pgocache.New(pgocache.DefaultExpiration, 0), WithInstanceName("namespace-root"))
+	tc.Namespace("namespace-child").Set(context.Background(), "a", 1, pgocache.DefaultExpiration)
+
+	rows, err := view.RetrieveData(GoCacheInstanceCallsView.Name)
+	if err != nil {
+		t.Fatal("Error retrieving view data:", err)
+	}
+	for _, row := range rows {
+		for _, tag := range row.Tags {
+			if tag.Key == GoCacheName && tag.Value == "namespace-root" {
+				t.Error("expected namespace calls not to be recorded under the root instance")
+			}
+			if tag.Key == GoCacheName && tag.Value == "namespace-child" {
+				return
+			}
+		}
+	}
+	t.Error("no calls recorded under the namespace instance name:", rows)
+}
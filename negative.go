@@ -0,0 +1,107 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// DefaultNegativeTTL is how long keys are cached as known to be absent when NegativeTTL is not set
+const DefaultNegativeTTL = 30 * time.Second
+
+// ErrAbsent is returned by a LoaderFunc to report that no item exists for a key, which is then cached as known to be
+// absent for NegativeTTL. GetOrLoad returns it for keys known to be absent.
+var ErrAbsent = errors.New("cache: item is known to be absent")
+
+// Absent is the value Get and GetWithExpiration return, along with found set to false, for keys cached as known to be
+// absent. It is stored in the underlying cache in place of the item.
+type Absent struct{}
+
+// IsAbsent reports whether v is the value of a key known to be absent
+func IsAbsent(v interface{}) bool {
+	_, ok := v.(Absent)
+	return ok
+}
+
+// lookupResult is the Result of a lookup returning v, distinguishing negative hits from misses
+func lookupResult(v interface{}, found bool) Result {
+	if IsAbsent(v) {
+		return Result{Status: StatusNegativeHit}
+	}
+	return FoundResult(found).WithValue(v)
+}
+
+// SetAbsent caches k as known to be absent for NegativeTTL, replacing any item stored under it
+func (w *Wrapper) SetAbsent(ctx context.Context, k string) {
+	ctx, end := w.startKeyOp(ctx, "go.cache.setabsent", w.options.SetAbsent, k, nil)
+	defer func() {
+		end(CalledResult())
+	}()
+
+	k = w.key(k)
+	w.fill(ctx, k, Absent{}, w.negativeTTL())
+}
+
+func (w *Wrapper) negativeTTL() time.Duration {
+	if w.options.NegativeTTL > 0 {
+		return w.options.NegativeTTL
+	}
+	return DefaultNegativeTTL
+}
+
+// negatives tracks the keys known to be absent in the underlying cache so that they can be left out of item counts.
+// Keys are tracked rather than counted as go-cache hides expired items, a write over an expired key known to be
+// absent cannot tell that it replaced one.
+type negatives struct {
+	mu   sync.Mutex
+	keys map[string]struct{}
+}
+
+func newNegatives() *negatives {
+	return &negatives{
+		keys: make(map[string]struct{}),
+	}
+}
+
+// stored records that x was stored under k
+func (c *negatives) stored(k string, x interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if IsAbsent(x) {
+		c.keys[k] = struct{}{}
+	} else {
+		delete(c.keys, k)
+	}
+}
+
+// removed records that v left the cache from k
+func (c *negatives) removed(k string, v interface{}) {
+	if !IsAbsent(v) {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.keys, k)
+}
+
+func (c *negatives) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.keys = make(map[string]struct{})
+}
+
+func (c *negatives) count() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.keys)
+}
+
+// count returns the number of items in the underlying cache, including expired items not yet deleted but leaving out
+// the keys known to be absent
+func (w *Wrapper) count() int {
+	if n := w.Cache.ItemCount() - w.negatives.count(); n > 0 {
+		return n
+	}
+	return 0
+}
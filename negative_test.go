@@ -0,0 +1,288 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	pgocache "github.com/patrickmn/go-cache"
+	"go.opencensus.io/stats/view"
+)
+
+func TestGetOrLoadNegative(t *testing.T) {
+	tc := Wrap(pgocache.New(pgocache.DefaultExpiration, 0), WithNegativeTTL(50*time.Millisecond))
+
+	var calls int32
+	loader := func(ctx context.Context) (interface{}, time.Duration, error) {
+		atomic.AddInt32(&calls, 1)
+		return nil, 0, ErrAbsent
+	}
+
+	for i := 0; i < 3; i++ {
+		if v, err := tc.GetOrLoad(context.Background(), "a", loader); !errors.Is(err, ErrAbsent) || v != nil {
+			t.Fatal("expected ErrAbsent, got:", v, err)
+		}
+	}
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Error("expected the loader to be called once while the key is known to be absent, got:", n)
+	}
+
+	v, found := tc.Get(context.Background(), "a")
+	if found || !IsAbsent(v) {
+		t.Error("expected Get to return Absent for a key known to be absent, got:", v, found)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	if _, err := tc.GetOrLoad(context.Background(), "a", loader); !errors.Is(err, ErrAbsent) {
+		t.Fatal("expected ErrAbsent, got:", err)
+	}
+	if n := atomic.LoadInt32(&calls); n != 2 {
+		t.Error("expected the loader to be called again once the negative entry expired, got:", n)
+	}
+}
+
+func TestSetAbsent(t *testing.T) {
+	r := &recordingInstrumenter{}
+	tc := Wrap(pgocache.New(pgocache.DefaultExpiration, 0), WithInstrumenter(r))
+
+	tc.Set(context.Background(), "a", 1, pgocache.DefaultExpiration)
+	tc.SetAbsent(context.Background(), "a")
+	if v, found := tc.Get(context.Background(), "a"); found || !IsAbsent(v) {
+		t.Error("expected SetAbsent to replace the item, got:", v, found)
+	}
+	if _, exp, _ := tc.GetWithExpiration(context.Background(), "a"); time.Until(exp) > DefaultNegativeTTL {
+		t.Error("expected the negative entry to expire within DefaultNegativeTTL, got:", exp)
+	}
+
+	v, err := NewTyped[int](tc).GetOrLoad(context.Background(), "a", func(ctx context.Context) (int, time.Duration, error) {
+		t.Error("unexpected loader call for a key known to be absent")
+		return 0, 0, nil
+	})
+	if !errors.Is(err, ErrAbsent) || v != 0 {
+		t.Error("expected ErrAbsent from Typed.GetOrLoad, got:", v, err)
+	}
+
+	var statuses []string
+	for _, op := range r.results()[2:] {
+		statuses = append(statuses, op.res.Status)
+	}
+	if len(statuses) != 3 || statuses[0] != StatusNegativeHit || statuses[1] != StatusNegativeHit || statuses[2] != StatusNegativeHit {
+		t.Error("expected negative hits, got:", statuses)
+	}
+}
+
+func TestNegativeHitLookupsView(t *testing.T) {
+	if err := view.Register(GoCacheLookupsView); err != nil {
+		t.Fatal("Error registering view:", err)
+	}
+	defer view.Unregister(GoCacheLookupsView)
+
+	tc := Wrap(pgocache.New(pgocache.DefaultExpiration, 0), WithInstanceName("negative-view"))
+	tc.SetAbsent(context.Background(), "a")
+	tc.Get(context.Background(), "a")
+	tc.Get(context.Background(), "b")
+
+	rows, err := view.RetrieveData(GoCacheLookupsView.Name)
+	if err != nil {
+		t.Fatal("Error retrieving view data:", err)
+	}
+	lookups := map[string]float64{}
+	for _, row := range rows {
+		var name, status string
+		for _, tag := range row.Tags {
+			switch tag.Key {
+			case GoCacheName:
+				name = tag.Value
+			case GoCacheStatus:
+				status = tag.Value
+			}
+		}
+		if name == "negative-view" {
+			lookups[status] += row.Data.(*view.SumData).Value
+		}
+	}
+	if lookups[StatusNegativeHit] != 1 || lookups[StatusNotFound] != 1 || lookups[StatusFound] != 0 {
+		t.Error("expected a negative hit and a miss, got:", lookups)
+	}
+}
+
+func TestAbsentNotAnItem(t *testing.T) {
+	tc := Wrap(pgocache.New(pgocache.DefaultExpiration, 0), WithMaxItems(2), WithNegativeTTL(time.Millisecond))
+	ctx := context.Background()
+
+	var evicted int32
+	tc.OnEvicted(ctx, func(string, interface{}) {
+		atomic.AddInt32(&evicted, 1)
+	})
+	events, cancel := tc.Watch(ctx, "*")
+	defer cancel()
+
+	tc.Set(ctx, "a", 1, pgocache.DefaultExpiration)
+	tc.SetAbsent(ctx, "b")
+	tc.SetAbsent(ctx, "c")
+	tc.Set(ctx, "d", 2, pgocache.DefaultExpiration)
+
+	if n := tc.ItemCount(ctx); n != 2 {
+		t.Error("expected keys known to be absent not to be counted, got:", n)
+	}
+	if items := tc.Items(ctx); len(items) != 2 {
+		t.Error("expected keys known to be absent to be left out of Items, got:", items)
+	}
+	if keys := tc.Keys(ctx, ""); len(keys) != 2 {
+		t.Error("expected keys known to be absent to be left out of Keys, got:", keys)
+	}
+	if keys, _, _ := tc.Scan(ctx, "*", "", 0); len(keys) != 2 {
+		t.Error("expected keys known to be absent to be left out of Scan, got:", keys)
+	}
+	if _, found := tc.Get(ctx, "a"); !found {
+		t.Error("expected keys known to be absent not to count toward MaxItems")
+	}
+	if items, err := NewTyped[int](tc).Items(ctx); err != nil || len(items) != 2 {
+		t.Error("expected typed items to skip keys known to be absent, got:", items, err)
+	}
+	if n := tc.Namespace("ns").ItemCount(ctx); n != 0 {
+		t.Error("expected an empty namespace, got:", n)
+	}
+
+	<-time.After(5 * time.Millisecond)
+	tc.DeleteExpired(ctx)
+	if n := tc.negatives.count(); n != 0 {
+		t.Error("expected expired keys known to be absent to be uncounted, got:", n)
+	}
+	if n := atomic.LoadInt32(&evicted); n != 0 {
+		t.Error("expected keys known to be absent not to reach OnEvicted, got:", n)
+	}
+
+	cancel()
+	for e := range events {
+		if e.Key == "b" || e.Key == "c" {
+			t.Error("expected no event for keys known to be absent, got:", e)
+		}
+	}
+}
+
+func TestAbsentOverwrittenOnceExpired(t *testing.T) {
+	tc := Wrap(pgocache.New(pgocache.DefaultExpiration, 0), WithMaxItems(2), WithNegativeTTL(5*time.Millisecond))
+	ctx := context.Background()
+
+	for _, k := range []string{"a", "b", "c"} {
+		tc.SetAbsent(ctx, k)
+	}
+	<-time.After(10 * time.Millisecond)
+	for i, k := range []string{"a", "b", "c", "d", "e"} {
+		tc.Set(ctx, k, i, pgocache.DefaultExpiration)
+	}
+
+	if n := tc.ItemCount(ctx); n != 2 {
+		t.Error("expected 2 items, got:", n)
+	}
+	if n := tc.Cache.ItemCount(); n != 2 {
+		t.Error("expected the cache to be kept within MaxItems, got:", n)
+	}
+	if n := tc.negatives.count(); n != 0 {
+		t.Error("expected overwritten keys known to be absent to be uncounted, got:", n)
+	}
+}
+
+func TestDeletePrefixAbsent(t *testing.T) {
+	tc := Wrap(pgocache.New(pgocache.DefaultExpiration, 0))
+	tc.Set(context.Background(), "user:1", 1, pgocache.DefaultExpiration)
+	tc.SetAbsent(context.Background(), "user:2")
+
+	if n := tc.DeletePrefix(context.Background(), "user:"); n != 1 {
+		t.Error("expected only the item to be counted, got:", n)
+	}
+	if _, found := tc.Cache.Get("user:2"); found {
+		t.Error("expected the key known to be absent to be deleted")
+	}
+	if n := tc.negatives.count(); n != 0 {
+		t.Error("expected no keys known to be absent left, got:", n)
+	}
+}
+
+func TestAddAbsent(t *testing.T) {
+	tc := Wrap(pgocache.New(pgocache.DefaultExpiration, 0))
+	tc.SetAbsent(context.Background(), "a")
+
+	if err := tc.Add(context.Background(), "a", 1, pgocache.DefaultExpiration); err != nil {
+		t.Fatal("expected Add to store over a key known to be absent, got:", err)
+	}
+	if v, found := tc.Get(context.Background(), "a"); !found || v != 1 {
+		t.Error("expected the added item, got:", v, found)
+	}
+	if n := tc.ItemCount(context.Background()); n != 1 {
+		t.Error("expected the added item to be counted, got:", n)
+	}
+}
+
+func TestReplaceAbsent(t *testing.T) {
+	tc := Wrap(pgocache.New(pgocache.DefaultExpiration, 0))
+	tc.SetAbsent(context.Background(), "a")
+
+	if err := tc.Replace(context.Background(), "a", 1, pgocache.DefaultExpiration); err == nil {
+		t.Error("expected Replace to fail for a key known to be absent")
+	}
+	if v, found := tc.Get(context.Background(), "a"); found || !IsAbsent(v) {
+		t.Error("expected the key to still be known to be absent, got:", v, found)
+	}
+}
+
+func TestUpdateAbsent(t *testing.T) {
+	tc := Wrap(pgocache.New(pgocache.DefaultExpiration, 0))
+	tc.SetAbsent(context.Background(), "a")
+
+	v, err := tc.Update(context.Background(), "a", func(old interface{}, found bool) (interface{}, time.Duration, error) {
+		if found || old != nil {
+			t.Error("expected a key known to be absent to be passed as missing, got:", old, found)
+		}
+		return 1, pgocache.DefaultExpiration, nil
+	})
+	if err != nil || v != 1 {
+		t.Error("unexpected update:", v, err)
+	}
+}
+
+func TestCompareAndSwapAbsent(t *testing.T) {
+	tc := Wrap(pgocache.New(pgocache.DefaultExpiration, 0))
+	tc.SetAbsent(context.Background(), "a")
+
+	if swapped, err := tc.CompareAndSwap(context.Background(), "a", Absent{}, 1); swapped || err != nil {
+		t.Error("expected no swap of a key known to be absent, got:", swapped, err)
+	}
+}
+
+func TestTouchAbsent(t *testing.T) {
+	tc := Wrap(pgocache.New(pgocache.DefaultExpiration, 0))
+	tc.SetAbsent(context.Background(), "a")
+
+	if err := tc.Touch(context.Background(), "a", time.Hour); !errors.Is(err, ErrNotFound) {
+		t.Error("expected ErrNotFound touching a key known to be absent, got:", err)
+	}
+}
+
+func TestIncrementAbsent(t *testing.T) {
+	tc := Wrap(pgocache.New(pgocache.DefaultExpiration, 0))
+	tc.SetAbsent(context.Background(), "a")
+
+	missing := tc.Increment(context.Background(), "b", 1)
+	if err := tc.Increment(context.Background(), "a", 1); err == nil || err.Error() != "Item a not found" || missing.Error() != "Item b not found" {
+		t.Error("expected incrementing a key known to be absent to fail as a missing key does, got:", err, missing)
+	}
+	if _, err := tc.DecrementInt(context.Background(), "a", 1); err == nil || err.Error() != "Item a not found" {
+		t.Error("expected decrementing a key known to be absent to fail as a missing key does, got:", err)
+	}
+}
+
+func TestSetIfVersionAbsent(t *testing.T) {
+	tc := Wrap(pgocache.New(pgocache.DefaultExpiration, 0))
+	tc.SetAbsent(context.Background(), "a")
+
+	if _, err := tc.SetIfVersion(context.Background(), "a", 1, pgocache.DefaultExpiration, 0); err != nil {
+		t.Fatal("expected version 0 to store over a key known to be absent, got:", err)
+	}
+	if v, found := tc.Get(context.Background(), "a"); !found || v != 1 {
+		t.Error("expected the stored item, got:", v, found)
+	}
+}
@@ -7,14 +7,22 @@ import (
 	"go.opencensus.io/stats"
 	"go.opencensus.io/stats/view"
 	"go.opencensus.io/tag"
+	"go.opentelemetry.io/otel/metric"
 )
 
+// The following values of the GoCacheStatus tag describe the outcome of a call
 const (
-	statusCalled   = "CALLED"
-	statusFound    = "FOUND"
-	statusNotFound = "NOT_FOUND"
-	statusError    = "ERROR"
-	statusOK       = "OK"
+	StatusCalled   = "CALLED"
+	StatusFound    = "FOUND"
+	StatusNotFound = "NOT_FOUND"
+	StatusError    = "ERROR"
+	StatusOK       = "OK"
+
+	// StatusTypeMismatch is the status of Typed calls finding an item of another type
+	StatusTypeMismatch = "TYPE_MISMATCH"
+
+	// StatusNegativeHit is the status of lookups finding a key cached as known to be absent
+	StatusNegativeHit = "NEGATIVE_HIT"
 )
 
 // The following tags are aooplied to stats recorded by this package
@@ -28,12 +36,48 @@ var (
 	// GoCacheStatus identifies found v.s not found.
 	GoCacheStatus, _ = tag.NewKey("go_cache_status")
 
+	// GoCacheEvictionReason identifies why an item was evicted.
+	GoCacheEvictionReason, _ = tag.NewKey("go_cache_eviction_reason")
+
+	// GoCacheKeyspace is the keyspace of the key a call operates on, see WithKeyClassifier.
+	GoCacheKeyspace, _ = tag.NewKey("go_cache_keyspace")
+
 	DefaultTags = []tag.Key{GoCacheMethod, GoCacheStatus}
+
+	// InstanceTags extends DefaultTags with the cache instance name so that instances can be told apart
+	InstanceTags = []tag.Key{GoCacheName, GoCacheMethod, GoCacheStatus}
+
+	// KeyspaceTags extends InstanceTags with the keyspace of the key a call operates on
+	KeyspaceTags = []tag.Key{GoCacheName, GoCacheKeyspace, GoCacheMethod, GoCacheStatus}
 )
 
 // The following measures are supported for use in custom views.
 var (
 	MeasureLatencyMs = stats.Int64("go.cache/latency", "The latency of calls in milliseconds", stats.UnitMilliseconds)
+
+	// MeasureLatencyMsFloat records latency in fractional milliseconds, unlike MeasureLatencyMs
+	// it can resolve the sub-millisecond latency of in memory calls.
+	MeasureLatencyMsFloat = stats.Float64("go.cache/latency_float", "The latency of calls in fractional milliseconds", stats.UnitMilliseconds)
+
+	// MeasureLookups counts the lookups made by Get, GetWithExpiration, GetOrLoad and GetMulti, the status tag tells hits
+	// from misses and negative hits
+	MeasureLookups = stats.Int64("go.cache/lookups", "The number of cache lookups", stats.UnitDimensionless)
+
+	MeasureStaleServes = stats.Int64("go.cache/stale_serves", "The number of stale items served while being refreshed", stats.UnitDimensionless)
+
+	MeasureRefreshFailures = stats.Int64("go.cache/refresh_failures", "The number of failed background refreshes", stats.UnitDimensionless)
+
+	MeasureEvictions = stats.Int64("go.cache/evictions", "The number of items evicted from the cache", stats.UnitDimensionless)
+
+	MeasureItemCount = stats.Int64("go.cache/item_count", "The number of items in the cache", stats.UnitDimensionless)
+
+	MeasureBytes = stats.Int64("go.cache/bytes", "The estimated size of the items in the cache", stats.UnitBytes)
+
+	MeasureStoreQueueDepth = stats.Int64("go.cache/store_queue_depth", "The number of writes queued for the Store", stats.UnitDimensionless)
+
+	MeasureStoreFailures = stats.Int64("go.cache/store_failures", "The number of writes to the Store that failed, after exhausting retries when writing behind", stats.UnitDimensionless)
+
+	MeasureWatchDrops = stats.Int64("go.cache/watch_drops", "The number of events dropped for slow Watch subscribers", stats.UnitDimensionless)
 )
 
 // Default distributions used by views in this package
@@ -93,7 +137,139 @@ var (
 		TagKeys:     DefaultTags,
 	}
 
-	DefaultViews = []*view.View{GoCacheLatencyView, GoCacheCallsView}
+	GoCacheFloatLatencyView = &view.View{
+		Name:        "go.cache/client/latency_float",
+		Description: "The distribution of latency of various calls in fractional milliseconds",
+		Measure:     MeasureLatencyMsFloat,
+		Aggregation: DefaultMillisecondsDistribution,
+		TagKeys:     DefaultTags,
+	}
+
+	GoCacheInstanceLatencyView = &view.View{
+		Name:        "go.cache/client/instance/latency",
+		Description: "The distribution of latency of various calls in fractional milliseconds by cache instance",
+		Measure:     MeasureLatencyMsFloat,
+		Aggregation: DefaultMillisecondsDistribution,
+		TagKeys:     InstanceTags,
+	}
+
+	GoCacheInstanceCallsView = &view.View{
+		Name:        "go.cache/client/instance/calls",
+		Description: "The number of various calls of methods by cache instance",
+		Measure:     MeasureLatencyMsFloat,
+		Aggregation: view.Count(),
+		TagKeys:     InstanceTags,
+	}
+
+	// GoCacheLookupsView counts hits, misses and negative hits by cache instance, the hit ratio is the FOUND count over
+	// the total
+	GoCacheLookupsView = &view.View{
+		Name:        "go.cache/client/lookups",
+		Description: "The number of cache hits and misses by cache instance",
+		Measure:     MeasureLookups,
+		Aggregation: view.Sum(),
+		TagKeys:     InstanceTags,
+	}
+
+	GoCacheKeyspaceCallsView = &view.View{
+		Name:        "go.cache/client/keyspace/calls",
+		Description: "The number of various calls of methods by cache instance and keyspace",
+		Measure:     MeasureLatencyMsFloat,
+		Aggregation: view.Count(),
+		TagKeys:     KeyspaceTags,
+	}
+
+	// GoCacheKeyspaceLookupsView counts hits and misses by cache instance and keyspace
+	GoCacheKeyspaceLookupsView = &view.View{
+		Name:        "go.cache/client/keyspace/lookups",
+		Description: "The number of cache hits and misses by cache instance and keyspace",
+		Measure:     MeasureLookups,
+		Aggregation: view.Sum(),
+		TagKeys:     KeyspaceTags,
+	}
+
+	GoCacheStaleServesView = &view.View{
+		Name:        "go.cache/client/stale_serves",
+		Description: "The number of stale items served while being refreshed",
+		Measure:     MeasureStaleServes,
+		Aggregation: view.Count(),
+		TagKeys:     DefaultTags,
+	}
+
+	GoCacheRefreshFailuresView = &view.View{
+		Name:        "go.cache/client/refresh_failures",
+		Description: "The number of failed background refreshes",
+		Measure:     MeasureRefreshFailures,
+		Aggregation: view.Count(),
+		TagKeys:     DefaultTags,
+	}
+
+	GoCacheEvictionsView = &view.View{
+		Name:        "go.cache/client/evictions",
+		Description: "The number of items evicted from the cache by reason",
+		Measure:     MeasureEvictions,
+		Aggregation: view.Count(),
+		TagKeys:     []tag.Key{GoCacheName, GoCacheEvictionReason},
+	}
+
+	GoCacheItemCountView = &view.View{
+		Name:        "go.cache/client/item_count",
+		Description: "The number of items in the cache",
+		Measure:     MeasureItemCount,
+		Aggregation: view.LastValue(),
+		TagKeys:     []tag.Key{GoCacheName},
+	}
+
+	GoCacheBytesView = &view.View{
+		Name:        "go.cache/client/bytes",
+		Description: "The estimated size of the items in the cache",
+		Measure:     MeasureBytes,
+		Aggregation: view.LastValue(),
+		TagKeys:     []tag.Key{GoCacheName},
+	}
+
+	GoCacheStoreQueueDepthView = &view.View{
+		Name:        "go.cache/client/store_queue_depth",
+		Description: "The number of writes queued for the Store",
+		Measure:     MeasureStoreQueueDepth,
+		Aggregation: view.LastValue(),
+		TagKeys:     []tag.Key{GoCacheName},
+	}
+
+	GoCacheStoreFailuresView = &view.View{
+		Name:        "go.cache/client/store_failures",
+		Description: "The number of writes to the Store that failed, after exhausting retries when writing behind",
+		Measure:     MeasureStoreFailures,
+		Aggregation: view.Count(),
+		TagKeys:     []tag.Key{GoCacheName},
+	}
+
+	GoCacheWatchDropsView = &view.View{
+		Name:        "go.cache/client/watch_drops",
+		Description: "The number of events dropped for slow Watch subscribers",
+		Measure:     MeasureWatchDrops,
+		Aggregation: view.Sum(),
+		TagKeys:     []tag.Key{GoCacheName},
+	}
+
+	DefaultViews = []*view.View{
+		GoCacheLatencyView,
+		GoCacheCallsView,
+		GoCacheFloatLatencyView,
+		GoCacheInstanceLatencyView,
+		GoCacheInstanceCallsView,
+		GoCacheLookupsView,
+		GoCacheKeyspaceCallsView,
+		GoCacheKeyspaceLookupsView,
+		GoCacheStaleServesView,
+		GoCacheRefreshFailuresView,
+		GoCacheEvictionsView,
+		GoCacheItemCountView,
+		GoCacheBytesView,
+		GoCacheStoreQueueDepthView,
+		GoCacheStoreFailuresView,
+		GoCacheWatchDropsView,
+	}
 )
 
 // RegisterAllViews registers all the cache views to enable collection of stats
@@ -101,63 +277,173 @@ func RegisterAllViews() error {
 	return view.Register(DefaultViews...)
 }
 
-func recordCallStats(ctx context.Context, method string, instanceName string) func() {
+// recordCallResult starts timing a call to method on k, the returned function records the call with its Result
+func recordCallResult(ctx context.Context, method string, k string, options TraceOptions) func(res Result) {
 	var startTime = time.Now()
 
-	return func() {
-		var (
-			timeSpentMs = time.Since(startTime).Milliseconds()
-			tags        = []tag.Mutator{
-				tag.Insert(GoCacheName, instanceName),
-				tag.Insert(GoCacheMethod, method),
-				tag.Insert(GoCacheStatus, statusCalled),
-			}
-		)
+	return func(res Result) {
+		var lookups = map[string]int64{
+			StatusFound:    int64(res.Hits),
+			StatusNotFound: int64(res.Misses),
+		}
+		switch res.Status {
+		case StatusFound, StatusNotFound, StatusNegativeHit:
+			lookups[res.Status]++
+		}
 
-		_ = stats.RecordWithTags(ctx, tags, MeasureLatencyMs.M(timeSpentMs))
+		recordCall(ctx, method, res.Status, options.keyspaces.classify(k), options, time.Since(startTime), lookups)
 	}
 }
 
-func recordCallFoundStats(ctx context.Context, method string, instanceName string) func(found bool) {
-	var startTime = time.Now()
+// recordCall records a call to method through OpenTelemetry when a MeterProvider is configured, and OpenCensus otherwise.
+// The lookups made by the call are counted by their status. The keyspace tag is omitted when keyspace is empty.
+func recordCall(ctx context.Context, method string, status string, keyspace string, options TraceOptions, timeSpent time.Duration, lookups map[string]int64) {
+	if options.instruments != nil {
+		options.instruments.record(ctx, options.InstanceName, method, status, keyspace, timeSpent, lookups)
+		return
+	}
+
+	var tags = []tag.Mutator{
+		tag.Insert(GoCacheName, options.InstanceName),
+		tag.Insert(GoCacheMethod, method),
+		tag.Insert(GoCacheStatus, status),
+	}
+	if keyspace != "" {
+		tags = append(tags, tag.Insert(GoCacheKeyspace, keyspace))
+	}
 
-	return func(found bool) {
-		var (
-			timeSpentMs = time.Since(startTime).Milliseconds()
-			tags        = []tag.Mutator{
-				tag.Insert(GoCacheName, instanceName),
-				tag.Insert(GoCacheMethod, method),
-			}
-		)
-
-		if found {
-			tags = append(tags, tag.Insert(GoCacheStatus, statusFound))
-		} else {
-			tags = append(tags, tag.Insert(GoCacheStatus, statusNotFound))
+	recordLatency(ctx, tags, timeSpent)
+	for lookupStatus, n := range lookups {
+		if n > 0 {
+			_ = stats.RecordWithTags(ctx, append(tags, tag.Upsert(GoCacheStatus, lookupStatus)), MeasureLookups.M(n))
 		}
+	}
+}
+
+// recordLatency records the time spent on a call to both latency measures
+func recordLatency(ctx context.Context, tags []tag.Mutator, timeSpent time.Duration) {
+	_ = stats.RecordWithTags(ctx, tags,
+		MeasureLatencyMs.M(timeSpent.Milliseconds()),
+		MeasureLatencyMsFloat.M(float64(timeSpent)/float64(time.Millisecond)),
+	)
+}
+
+func recordStaleServe(ctx context.Context, method string, options TraceOptions) {
+	if i := options.instruments; i != nil {
+		i.staleServes.Add(ctx, 1, metric.WithAttributes(
+			otelNameKey.String(options.InstanceName),
+			otelMethodKey.String(method),
+			otelStatusKey.String(StatusFound),
+		))
+		return
+	}
 
-		_ = stats.RecordWithTags(ctx, tags, MeasureLatencyMs.M(timeSpentMs))
+	var tags = []tag.Mutator{
+		tag.Insert(GoCacheName, options.InstanceName),
+		tag.Insert(GoCacheMethod, method),
+		tag.Insert(GoCacheStatus, StatusFound),
 	}
+
+	_ = stats.RecordWithTags(ctx, tags, MeasureStaleServes.M(1))
 }
 
-func recordCallErrorStatus(ctx context.Context, method string, instanceName string) func(err error) {
-	var startTime = time.Now()
+func recordRefreshFailure(ctx context.Context, options TraceOptions) {
+	if i := options.instruments; i != nil {
+		i.refreshFailures.Add(ctx, 1, metric.WithAttributes(
+			otelNameKey.String(options.InstanceName),
+			otelMethodKey.String("go.cache.refresh"),
+			otelStatusKey.String(StatusError),
+		))
+		return
+	}
 
-	return func(err error) {
-		var (
-			timeSpentMs = time.Since(startTime).Milliseconds()
-			tags        = []tag.Mutator{
-				tag.Insert(GoCacheName, instanceName),
-				tag.Insert(GoCacheMethod, method),
-			}
-		)
-
-		if err != nil {
-			tags = append(tags, tag.Insert(GoCacheStatus, statusError))
-		} else {
-			tags = append(tags, tag.Insert(GoCacheStatus, statusOK))
-		}
+	var tags = []tag.Mutator{
+		tag.Insert(GoCacheName, options.InstanceName),
+		tag.Insert(GoCacheMethod, "go.cache.refresh"),
+		tag.Insert(GoCacheStatus, StatusError),
+	}
+
+	_ = stats.RecordWithTags(ctx, tags, MeasureRefreshFailures.M(1))
+}
+
+func recordEviction(ctx context.Context, reason EvictionReason, options TraceOptions) {
+	if i := options.instruments; i != nil {
+		i.evictions.Add(ctx, 1, metric.WithAttributes(
+			otelNameKey.String(options.InstanceName),
+			otelReasonKey.String(string(reason)),
+		))
+		return
+	}
+
+	var tags = []tag.Mutator{
+		tag.Insert(GoCacheName, options.InstanceName),
+		tag.Insert(GoCacheEvictionReason, string(reason)),
+	}
+
+	_ = stats.RecordWithTags(ctx, tags, MeasureEvictions.M(1))
+}
 
-		_ = stats.RecordWithTags(ctx, tags, MeasureLatencyMs.M(timeSpentMs))
+func recordItemCount(ctx context.Context, options TraceOptions, items int64) {
+	if i := options.instruments; i != nil {
+		i.set(otelItemCount, options.InstanceName, items)
+		return
 	}
+
+	var tags = []tag.Mutator{
+		tag.Insert(GoCacheName, options.InstanceName),
+	}
+
+	_ = stats.RecordWithTags(ctx, tags, MeasureItemCount.M(items))
+}
+
+func recordBytes(ctx context.Context, options TraceOptions, bytes int64) {
+	if i := options.instruments; i != nil {
+		i.set(otelBytes, options.InstanceName, bytes)
+		return
+	}
+
+	var tags = []tag.Mutator{
+		tag.Insert(GoCacheName, options.InstanceName),
+	}
+
+	_ = stats.RecordWithTags(ctx, tags, MeasureBytes.M(bytes))
+}
+
+func recordStoreQueueDepth(ctx context.Context, options TraceOptions, depth int64) {
+	if i := options.instruments; i != nil {
+		i.set(otelStoreQueueDepth, options.InstanceName, depth)
+		return
+	}
+
+	var tags = []tag.Mutator{
+		tag.Insert(GoCacheName, options.InstanceName),
+	}
+
+	_ = stats.RecordWithTags(ctx, tags, MeasureStoreQueueDepth.M(depth))
+}
+
+func recordStoreFailure(ctx context.Context, options TraceOptions) {
+	if i := options.instruments; i != nil {
+		i.storeFailures.Add(ctx, 1, metric.WithAttributes(otelNameKey.String(options.InstanceName)))
+		return
+	}
+
+	var tags = []tag.Mutator{
+		tag.Insert(GoCacheName, options.InstanceName),
+	}
+
+	_ = stats.RecordWithTags(ctx, tags, MeasureStoreFailures.M(1))
+}
+
+func recordWatchDrop(ctx context.Context, options TraceOptions) {
+	if i := options.instruments; i != nil {
+		i.watchDrops.Add(ctx, 1, metric.WithAttributes(otelNameKey.String(options.InstanceName)))
+		return
+	}
+
+	var tags = []tag.Mutator{
+		tag.Insert(GoCacheName, options.InstanceName),
+	}
+
+	_ = stats.RecordWithTags(ctx, tags, MeasureWatchDrops.M(1))
 }
@@ -0,0 +1,82 @@
+package cache
+
+import (
+	"context"
+	"testing"
+
+	pgocache "github.com/patrickmn/go-cache"
+	"go.opencensus.io/stats/view"
+)
+
+func TestFloatLatency(t *testing.T) {
+	if err := view.Register(GoCacheFloatLatencyView); err != nil {
+		t.Fatal("Error registering view:", err)
+	}
+	defer view.Unregister(GoCacheFloatLatencyView)
+
+	tc := Wrap(pgocache.New(pgocache.DefaultExpiration, 0))
+	tc.Set(context.Background(), "a", 1, pgocache.DefaultExpiration)
+	tc.Get(context.Background(), "a")
+
+	rows, err := view.RetrieveData(GoCacheFloatLatencyView.Name)
+	if err != nil {
+		t.Fatal("Error retrieving view data:", err)
+	}
+	for _, row := range rows {
+		for _, tag := range row.Tags {
+			if tag.Key == GoCacheMethod && tag.Value == "go.cache.get" {
+				data := row.Data.(*view.DistributionData)
+				if data.Count != 1 || data.Mean <= 0 {
+					t.Error("expected a single non-zero latency, got:", data.Count, data.Mean)
+				}
+				return
+			}
+		}
+	}
+	t.Error("no latency recorded for go.cache.get:", rows)
+}
+
+func TestLookupsView(t *testing.T) {
+	if err := view.Register(GoCacheLookupsView); err != nil {
+		t.Fatal("Error registering view:", err)
+	}
+	defer view.Unregister(GoCacheLookupsView)
+
+	tc := Wrap(pgocache.New(pgocache.DefaultExpiration, 0), WithInstanceName("lookups"))
+	tc.Set(context.Background(), "a", 1, pgocache.DefaultExpiration)
+	tc.Get(context.Background(), "a")
+	tc.Get(context.Background(), "a")
+	tc.GetWithExpiration(context.Background(), "b")
+
+	other := Wrap(pgocache.New(pgocache.DefaultExpiration, 0), WithInstanceName("other"))
+	other.Get(context.Background(), "a")
+
+	rows, err := view.RetrieveData(GoCacheLookupsView.Name)
+	if err != nil {
+		t.Fatal("Error retrieving view data:", err)
+	}
+	var hits, misses float64
+	for _, row := range rows {
+		var name, status string
+		for _, tag := range row.Tags {
+			switch tag.Key {
+			case GoCacheName:
+				name = tag.Value
+			case GoCacheStatus:
+				status = tag.Value
+			}
+		}
+		if name != "lookups" {
+			continue
+		}
+		switch status {
+		case StatusFound:
+			hits += row.Data.(*view.SumData).Value
+		case StatusNotFound:
+			misses += row.Data.(*view.SumData).Value
+		}
+	}
+	if hits != 2 || misses != 1 {
+		t.Errorf("expected 2 hits and 1 miss, got %v and %v", hits, misses)
+	}
+}
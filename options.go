@@ -1,6 +1,12 @@
 package cache
 
-import "go.opencensus.io/trace"
+import (
+	"time"
+
+	"go.opencensus.io/trace"
+	"go.opentelemetry.io/otel/metric"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
 
 const defaultInstanceName = "default"
 
@@ -25,111 +31,367 @@ type TraceOptions struct {
 	// Sampler to use when creating spans
 	Sampler trace.Sampler
 
+	// KeyAttribute, if set, maps the key of keyed calls to the cache.key span
+	// attribute. Keys are not attached by default as they may hold personal
+	// data, see RawKey, TruncatedKey, HashedKey and KeyPrefix.
+	KeyAttribute func(k string) string
+
+	// ValueAttributes, if set to true, attaches the type and estimated size
+	// of the values stored or returned by calls to their spans.
+	ValueAttributes bool
+
+	// KeyClassifier, if set, maps the key of keyed calls to the value of the
+	// GoCacheKeyspace tag, e.g. KeyPrefix(":") to tell "user:" keys from
+	// "session:" keys.
+	KeyClassifier func(k string) string
+
+	// MaxKeyspaces caps the number of distinct GoCacheKeyspace tag values,
+	// keys classified beyond the cap are tagged KeyspaceOther. Defaults to
+	// DefaultMaxKeyspaces.
+	MaxKeyspaces int
+
+	// keyspaces bounds the values produced by KeyClassifier, it is created by NewDefaultInstrumenter
+	keyspaces *keyspaces
+
+	// TracerProvider, if set, creates OpenTelemetry spans in place of
+	// OpenCensus spans.
+	TracerProvider oteltrace.TracerProvider
+
+	// MeterProvider, if set, records the metrics of the package through
+	// OpenTelemetry in place of the OpenCensus measures. Gauges report the
+	// last sampled value on each collection.
+	MeterProvider metric.MeterProvider
+
+	// instruments are created from MeterProvider by NewDefaultInstrumenter
+	instruments *otelInstruments
+
+	// Instrumenter, if set, replaces the default OpenCensus or OpenTelemetry
+	// instrumentation of Wrapper methods and the Signals they raise, such as
+	// evictions.
+	Instrumenter Instrumenter
+
+	// DefaultExpiration is the default expiration the wrapped cache was
+	// created with. go-cache does not expose it, it is needed to apply
+	// StaleTTL, TTL jitter, SetSliding and the cache.expiration span attribute
+	// to items stored with DefaultExpiration. Zero leaves those items to
+	// go-cache unchanged.
+	DefaultExpiration time.Duration
+
+	// StaleTTL is how long past its expiration an item may still be served
+	// while it is refreshed in the background by RefreshLoader.
+	StaleTTL time.Duration
+
+	// RefreshAhead starts a background refresh by RefreshLoader when an item
+	// is read within this long of its expiration.
+	RefreshAhead time.Duration
+
+	// RefreshLoader loads fresh values for stale or expiring items. Stale
+	// serving and refresh-ahead are disabled unless it is set.
+	RefreshLoader KeyLoaderFunc
+
+	// MaxItems is the maximum number of items held in the cache, items are
+	// evicted by EvictionPolicy once it is exceeded. Zero means unbounded.
+	MaxItems int
+
+	// MaxBytes is the maximum estimated size of the items set through the
+	// Wrapper, items are evicted by EvictionPolicy once it is exceeded. Zero
+	// means unbounded.
+	MaxBytes int64
+
+	// EvictionPolicy creates the policy used to choose items to evict when
+	// the cache is over capacity. Defaults to NewLRUPolicy.
+	EvictionPolicy func() EvictionPolicy
+
+	// Sizer estimates the size in bytes of an item for MaxBytes. Defaults to
+	// a reflection based estimate.
+	Sizer func(interface{}) int64
+
+	// CacheOnEvicted is the callback registered with the wrapped cache
+	// through pgocache.Cache.OnEvicted before it was wrapped. Wrap replaces
+	// it with its own, and calls it for every item go-cache removes.
+	CacheOnEvicted func(string, interface{})
+
+	// SampleInterval is how often the item count and estimated size of the
+	// cache are recorded. Zero disables sampling. Sampling runs until the
+	// Wrapper is closed.
+	SampleInterval time.Duration
+
+	// WatchBuffer is the number of events buffered for each Watch subscriber,
+	// events that do not fit are dropped. Defaults to DefaultWatchBuffer.
+	WatchBuffer int
+
+	// NegativeTTL is how long keys are cached as known to be absent by
+	// SetAbsent, or when a loader returns ErrAbsent. Defaults to
+	// DefaultNegativeTTL.
+	NegativeTTL time.Duration
+
+	// TTLJitter shortens the duration of items stored by Set, SetDefault,
+	// Add, Replace and SetMulti by a random fraction of up to TTLJitter, e.g.
+	// 0.1 for up to 10%, so that items stored together do not all expire
+	// together.
+	TTLJitter float64
+
+	// TTLJitterDuration shortens the duration of items like TTLJitter, by a
+	// random amount of up to TTLJitterDuration. When both are set the larger
+	// spread is used.
+	TTLJitterDuration time.Duration
+
+	// SlidingTTL, if set, makes reads push the expiration of items out to
+	// SlidingTTL from now, keeping items alive for as long as they are read.
+	// Items that never expire are left alone, and items stored by SetSliding
+	// slide by their own duration instead.
+	SlidingTTL time.Duration
+
+	// Store, if set, mirrors Set, SetDefault, SetWithErr and Delete calls to
+	// a persistent store and is read through on Get misses. Writes are made
+	// synchronously unless WriteBehind is set.
+	Store Store
+
+	// WriteBehind, if set to true, queues writes to Store and makes them in
+	// batches from a background goroutine, retrying failures. Queued writes
+	// are flushed when the Wrapper is closed.
+	WriteBehind bool
+
+	// WriteBehindInterval is the longest a queued write waits before being
+	// made. Defaults to DefaultWriteBehindInterval.
+	WriteBehindInterval time.Duration
+
+	// WriteBehindBatchSize is the number of queued writes that triggers a
+	// batch. Defaults to DefaultWriteBehindBatchSize.
+	WriteBehindBatchSize int
+
+	// StoreRetries is the number of times a failed write behind is retried.
+	StoreRetries int
+
 	// Setting the below options will control whether or not spans are created
 	// on their call.
-	Add               bool
-	Decrement         bool
-	DecrementFloat    bool
-	DecrementFloat32  bool
-	DecrementFloat64  bool
-	DecrementInt      bool
-	DecrementInt16    bool
-	DecrementInt32    bool
-	DecrementInt64    bool
-	DecrementInt8     bool
-	DecrementUint     bool
-	DecrementUint16   bool
-	DecrementUint32   bool
-	DecrementUint64   bool
-	DecrementUint8    bool
-	DecrementUintptr  bool
-	Delete            bool
-	DeleteExpired     bool
-	Flush             bool
-	Get               bool
-	GetWithExpiration bool
-	Increment         bool
-	IncrementFloat    bool
-	IncrementFloat32  bool
-	IncrementFloat64  bool
-	IncrementInt      bool
-	IncrementInt16    bool
-	IncrementInt32    bool
-	IncrementInt64    bool
-	IncrementInt8     bool
-	IncrementUint     bool
-	IncrementUint16   bool
-	IncrementUint32   bool
-	IncrementUint64   bool
-	IncrementUint8    bool
-	IncrementUintptr  bool
-	ItemCount         bool
-	Items             bool
-	Load              bool
-	LoadFile          bool
-	OnEvicted         bool
-	Replace           bool
-	Save              bool
-	SaveFile          bool
-	Set               bool
-	SetDefault        bool
-}
-
-// WithAllTraceOptions enables all available traceoptions
+	Add                    bool
+	AddEvictionListener    bool
+	CompareAndSwap         bool
+	Decrement              bool
+	DecrementFloat         bool
+	DecrementFloat32       bool
+	DecrementFloat64       bool
+	DecrementInt           bool
+	DecrementInt16         bool
+	DecrementInt32         bool
+	DecrementInt64         bool
+	DecrementInt8          bool
+	DecrementUint          bool
+	DecrementUint16        bool
+	DecrementUint32        bool
+	DecrementUint64        bool
+	DecrementUint8         bool
+	DecrementUintptr       bool
+	Delete                 bool
+	DeleteExpired          bool
+	DeleteMatching         bool
+	DeleteMulti            bool
+	DeletePrefix           bool
+	Flush                  bool
+	Get                    bool
+	GetMulti               bool
+	GetOrLoad              bool
+	GetVersioned           bool
+	GetWithExpiration      bool
+	Increment              bool
+	IncrementFloat         bool
+	IncrementFloat32       bool
+	IncrementFloat64       bool
+	IncrementInt           bool
+	IncrementInt16         bool
+	IncrementInt32         bool
+	IncrementInt64         bool
+	IncrementInt8          bool
+	IncrementUint          bool
+	IncrementUint16        bool
+	IncrementUint32        bool
+	IncrementUint64        bool
+	IncrementUint8         bool
+	IncrementUintptr       bool
+	InvalidateTag          bool
+	ItemCount              bool
+	Items                  bool
+	Keys                   bool
+	Load                   bool
+	LoadFile               bool
+	OnEvicted              bool
+	OnEvictedWithReason    bool
+	Refresh                bool
+	RemoveEvictionListener bool
+	Replace                bool
+	Save                   bool
+	SaveFile               bool
+	Scan                   bool
+	Set                    bool
+	SetAbsent              bool
+	SetDefault             bool
+	SetIfVersion           bool
+	SetMulti               bool
+	SetSliding             bool
+	SetWithErr             bool
+	SetWithTags            bool
+	StoreCalls             bool
+	Touch                  bool
+	Update                 bool
+	Watch                  bool
+}
+
+// WithAllTraceOptions enables all available traceoptions. Only the per
+// method options are set, other options such as MaxItems or Store are kept.
 func WithAllTraceOptions() TraceOption {
 	return func(o *TraceOptions) {
-		*o = AllTraceOptions
+		o.setMethods(AllTraceOptions)
 	}
 }
 
+// setMethods copies the options controlling whether spans are created on
+// each method from from, leaving the others unchanged
+func (o *TraceOptions) setMethods(from TraceOptions) {
+	o.Add = from.Add
+	o.AddEvictionListener = from.AddEvictionListener
+	o.CompareAndSwap = from.CompareAndSwap
+	o.Decrement = from.Decrement
+	o.DecrementFloat = from.DecrementFloat
+	o.DecrementFloat32 = from.DecrementFloat32
+	o.DecrementFloat64 = from.DecrementFloat64
+	o.DecrementInt = from.DecrementInt
+	o.DecrementInt16 = from.DecrementInt16
+	o.DecrementInt32 = from.DecrementInt32
+	o.DecrementInt64 = from.DecrementInt64
+	o.DecrementInt8 = from.DecrementInt8
+	o.DecrementUint = from.DecrementUint
+	o.DecrementUint16 = from.DecrementUint16
+	o.DecrementUint32 = from.DecrementUint32
+	o.DecrementUint64 = from.DecrementUint64
+	o.DecrementUint8 = from.DecrementUint8
+	o.DecrementUintptr = from.DecrementUintptr
+	o.Delete = from.Delete
+	o.DeleteExpired = from.DeleteExpired
+	o.DeleteMatching = from.DeleteMatching
+	o.DeleteMulti = from.DeleteMulti
+	o.DeletePrefix = from.DeletePrefix
+	o.Flush = from.Flush
+	o.Get = from.Get
+	o.GetMulti = from.GetMulti
+	o.GetOrLoad = from.GetOrLoad
+	o.GetVersioned = from.GetVersioned
+	o.GetWithExpiration = from.GetWithExpiration
+	o.Increment = from.Increment
+	o.IncrementFloat = from.IncrementFloat
+	o.IncrementFloat32 = from.IncrementFloat32
+	o.IncrementFloat64 = from.IncrementFloat64
+	o.IncrementInt = from.IncrementInt
+	o.IncrementInt16 = from.IncrementInt16
+	o.IncrementInt32 = from.IncrementInt32
+	o.IncrementInt64 = from.IncrementInt64
+	o.IncrementInt8 = from.IncrementInt8
+	o.IncrementUint = from.IncrementUint
+	o.IncrementUint16 = from.IncrementUint16
+	o.IncrementUint32 = from.IncrementUint32
+	o.IncrementUint64 = from.IncrementUint64
+	o.IncrementUint8 = from.IncrementUint8
+	o.IncrementUintptr = from.IncrementUintptr
+	o.InvalidateTag = from.InvalidateTag
+	o.ItemCount = from.ItemCount
+	o.Items = from.Items
+	o.Keys = from.Keys
+	o.Load = from.Load
+	o.LoadFile = from.LoadFile
+	o.OnEvicted = from.OnEvicted
+	o.OnEvictedWithReason = from.OnEvictedWithReason
+	o.Refresh = from.Refresh
+	o.RemoveEvictionListener = from.RemoveEvictionListener
+	o.Replace = from.Replace
+	o.Save = from.Save
+	o.SaveFile = from.SaveFile
+	o.Scan = from.Scan
+	o.Set = from.Set
+	o.SetAbsent = from.SetAbsent
+	o.SetDefault = from.SetDefault
+	o.SetIfVersion = from.SetIfVersion
+	o.SetMulti = from.SetMulti
+	o.SetSliding = from.SetSliding
+	o.SetWithErr = from.SetWithErr
+	o.SetWithTags = from.SetWithTags
+	o.StoreCalls = from.StoreCalls
+	o.Touch = from.Touch
+	o.Update = from.Update
+	o.Watch = from.Watch
+}
+
 // AllTraceOptions has all tracing options enabled
 var AllTraceOptions = TraceOptions{
-	Add:               true,
-	Decrement:         true,
-	DecrementFloat:    true,
-	DecrementFloat32:  true,
-	DecrementFloat64:  true,
-	DecrementInt:      true,
-	DecrementInt16:    true,
-	DecrementInt32:    true,
-	DecrementInt64:    true,
-	DecrementInt8:     true,
-	DecrementUint:     true,
-	DecrementUint16:   true,
-	DecrementUint32:   true,
-	DecrementUint64:   true,
-	DecrementUint8:    true,
-	DecrementUintptr:  true,
-	Delete:            true,
-	DeleteExpired:     true,
-	Flush:             true,
-	Get:               true,
-	GetWithExpiration: true,
-	Increment:         true,
-	IncrementFloat:    true,
-	IncrementFloat32:  true,
-	IncrementFloat64:  true,
-	IncrementInt:      true,
-	IncrementInt16:    true,
-	IncrementInt32:    true,
-	IncrementInt64:    true,
-	IncrementInt8:     true,
-	IncrementUint:     true,
-	IncrementUint16:   true,
-	IncrementUint32:   true,
-	IncrementUint64:   true,
-	IncrementUint8:    true,
-	IncrementUintptr:  true,
-	ItemCount:         true,
-	Items:             true,
-	Load:              true,
-	LoadFile:          true,
-	OnEvicted:         true,
-	Replace:           true,
-	Save:              true,
-	SaveFile:          true,
-	Set:               true,
-	SetDefault:        true,
+	Add:                    true,
+	AddEvictionListener:    true,
+	CompareAndSwap:         true,
+	Decrement:              true,
+	DecrementFloat:         true,
+	DecrementFloat32:       true,
+	DecrementFloat64:       true,
+	DecrementInt:           true,
+	DecrementInt16:         true,
+	DecrementInt32:         true,
+	DecrementInt64:         true,
+	DecrementInt8:          true,
+	DecrementUint:          true,
+	DecrementUint16:        true,
+	DecrementUint32:        true,
+	DecrementUint64:        true,
+	DecrementUint8:         true,
+	DecrementUintptr:       true,
+	Delete:                 true,
+	DeleteExpired:          true,
+	DeleteMatching:         true,
+	DeleteMulti:            true,
+	DeletePrefix:           true,
+	Flush:                  true,
+	Get:                    true,
+	GetMulti:               true,
+	GetOrLoad:              true,
+	GetVersioned:           true,
+	GetWithExpiration:      true,
+	Increment:              true,
+	IncrementFloat:         true,
+	IncrementFloat32:       true,
+	IncrementFloat64:       true,
+	IncrementInt:           true,
+	IncrementInt16:         true,
+	IncrementInt32:         true,
+	IncrementInt64:         true,
+	IncrementInt8:          true,
+	IncrementUint:          true,
+	IncrementUint16:        true,
+	IncrementUint32:        true,
+	IncrementUint64:        true,
+	IncrementUint8:         true,
+	IncrementUintptr:       true,
+	InvalidateTag:          true,
+	ItemCount:              true,
+	Items:                  true,
+	Keys:                   true,
+	Load:                   true,
+	LoadFile:               true,
+	OnEvicted:              true,
+	OnEvictedWithReason:    true,
+	Refresh:                true,
+	RemoveEvictionListener: true,
+	Replace:                true,
+	Save:                   true,
+	SaveFile:               true,
+	Scan:                   true,
+	Set:                    true,
+	SetAbsent:              true,
+	SetDefault:             true,
+	SetIfVersion:           true,
+	SetMulti:               true,
+	SetSliding:             true,
+	SetWithErr:             true,
+	SetWithTags:            true,
+	StoreCalls:             true,
+	Touch:                  true,
+	Update:                 true,
+	Watch:                  true,
 }
 
 // WithOptions sets the go-cache tracing options with a single TraceOptions object
@@ -152,6 +414,21 @@ func WithAllowRoot(b bool) TraceOption {
 	}
 }
 
+// WithKeyAttribute sets the function mapping keys to the cache.key span
+// attribute, e.g. RawKey, TruncatedKey(16), HashedKey(secret) or KeyPrefix(":")
+func WithKeyAttribute(f func(k string) string) TraceOption {
+	return func(o *TraceOptions) {
+		o.KeyAttribute = f
+	}
+}
+
+// WithValueAttributes if set to true, will attach the type and estimated size of values to spans
+func WithValueAttributes(b bool) TraceOption {
+	return func(o *TraceOptions) {
+		o.ValueAttributes = b
+	}
+}
+
 // WithInstanceName sets cache instance name.
 func WithInstanceName(instanceName string) TraceOption {
 	return func(o *TraceOptions) {
@@ -159,6 +436,184 @@ func WithInstanceName(instanceName string) TraceOption {
 	}
 }
 
+// WithDefaultExpiration sets the default expiration the wrapped cache was
+// created with, use pgocache.NoExpiration for caches whose items never expire
+// by default
+func WithDefaultExpiration(d time.Duration) TraceOption {
+	return func(o *TraceOptions) {
+		o.DefaultExpiration = d
+	}
+}
+
+// WithCacheOnEvicted keeps f, the callback registered with the wrapped cache
+// before it was wrapped, called for every item go-cache removes
+func WithCacheOnEvicted(f func(string, interface{})) TraceOption {
+	return func(o *TraceOptions) {
+		o.CacheOnEvicted = f
+	}
+}
+
+// WithStaleTTL sets how long past its expiration an item may still be served
+// while it is refreshed in the background. Requires WithRefreshLoader.
+func WithStaleTTL(d time.Duration) TraceOption {
+	return func(o *TraceOptions) {
+		o.StaleTTL = d
+	}
+}
+
+// WithRefreshAhead sets how long before its expiration a read will start a
+// background refresh of an item. Requires WithRefreshLoader.
+func WithRefreshAhead(d time.Duration) TraceOption {
+	return func(o *TraceOptions) {
+		o.RefreshAhead = d
+	}
+}
+
+// WithRefreshLoader sets the loader used to refresh stale or expiring items
+func WithRefreshLoader(f KeyLoaderFunc) TraceOption {
+	return func(o *TraceOptions) {
+		o.RefreshLoader = f
+	}
+}
+
+// WithMaxItems sets the maximum number of items held in the cache
+func WithMaxItems(n int) TraceOption {
+	return func(o *TraceOptions) {
+		o.MaxItems = n
+	}
+}
+
+// WithMaxBytes sets the maximum estimated size of the items held in the cache
+func WithMaxBytes(n int64) TraceOption {
+	return func(o *TraceOptions) {
+		o.MaxBytes = n
+	}
+}
+
+// WithEvictionPolicy sets the policy used to evict items when the cache is over capacity,
+// e.g. NewLRUPolicy, NewLFUPolicy or NewRandomPolicy
+func WithEvictionPolicy(f func() EvictionPolicy) TraceOption {
+	return func(o *TraceOptions) {
+		o.EvictionPolicy = f
+	}
+}
+
+// WithSizer sets the function used to estimate the size of items for WithMaxBytes
+func WithSizer(f func(interface{}) int64) TraceOption {
+	return func(o *TraceOptions) {
+		o.Sizer = f
+	}
+}
+
+// WithWatchBuffer sets the number of events buffered for each Watch subscriber
+func WithWatchBuffer(n int) TraceOption {
+	return func(o *TraceOptions) {
+		o.WatchBuffer = n
+	}
+}
+
+// WithNegativeTTL sets how long keys are cached as known to be absent
+func WithNegativeTTL(d time.Duration) TraceOption {
+	return func(o *TraceOptions) {
+		o.NegativeTTL = d
+	}
+}
+
+// WithTTLJitter shortens item durations by a random fraction of up to f
+func WithTTLJitter(f float64) TraceOption {
+	return func(o *TraceOptions) {
+		o.TTLJitter = f
+	}
+}
+
+// WithTTLJitterDuration shortens item durations by a random amount of up to d
+func WithTTLJitterDuration(d time.Duration) TraceOption {
+	return func(o *TraceOptions) {
+		o.TTLJitterDuration = d
+	}
+}
+
+// WithSlidingTTL makes reads extend the expiration of items to d from now
+func WithSlidingTTL(d time.Duration) TraceOption {
+	return func(o *TraceOptions) {
+		o.SlidingTTL = d
+	}
+}
+
+// WithWriteThrough mirrors writes to s synchronously, SetWithErr returns the errors of s
+func WithWriteThrough(s Store) TraceOption {
+	return func(o *TraceOptions) {
+		o.Store = s
+		o.WriteBehind = false
+	}
+}
+
+// WithWriteBehind mirrors writes to s in batches from a background goroutine
+func WithWriteBehind(s Store) TraceOption {
+	return func(o *TraceOptions) {
+		o.Store = s
+		o.WriteBehind = true
+	}
+}
+
+// WithWriteBehindInterval sets the longest a queued write waits before being made
+func WithWriteBehindInterval(d time.Duration) TraceOption {
+	return func(o *TraceOptions) {
+		o.WriteBehindInterval = d
+	}
+}
+
+// WithWriteBehindBatchSize sets the number of queued writes that triggers a batch
+func WithWriteBehindBatchSize(n int) TraceOption {
+	return func(o *TraceOptions) {
+		o.WriteBehindBatchSize = n
+	}
+}
+
+// WithStoreRetries sets the number of times a failed write behind is retried
+func WithStoreRetries(n int) TraceOption {
+	return func(o *TraceOptions) {
+		o.StoreRetries = n
+	}
+}
+
+// WithSampleInterval sets how often the item count and estimated size of the cache are recorded
+func WithSampleInterval(d time.Duration) TraceOption {
+	return func(o *TraceOptions) {
+		o.SampleInterval = d
+	}
+}
+
+// WithOpenTelemetry instruments the cache with OpenTelemetry instead of
+// OpenCensus. Either provider may be nil to keep using OpenCensus for it.
+func WithOpenTelemetry(tp oteltrace.TracerProvider, mp metric.MeterProvider) TraceOption {
+	return func(o *TraceOptions) {
+		o.TracerProvider = tp
+		o.MeterProvider = mp
+	}
+}
+
+// WithKeyClassifier sets the function mapping keys to the GoCacheKeyspace tag, e.g. KeyPrefix(":")
+func WithKeyClassifier(f func(k string) string) TraceOption {
+	return func(o *TraceOptions) {
+		o.KeyClassifier = f
+	}
+}
+
+// WithMaxKeyspaces sets the maximum number of distinct GoCacheKeyspace tag values
+func WithMaxKeyspaces(n int) TraceOption {
+	return func(o *TraceOptions) {
+		o.MaxKeyspaces = n
+	}
+}
+
+// WithInstrumenter sets the Instrumenter observing Wrapper methods, e.g. NoopInstrumenter
+func WithInstrumenter(i Instrumenter) TraceOption {
+	return func(o *TraceOptions) {
+		o.Instrumenter = i
+	}
+}
+
 // WithAdd if set to true, will allow spans on Add
 func WithAdd(b bool) TraceOption {
 	return func(o *TraceOptions) {
@@ -166,6 +621,20 @@ func WithAdd(b bool) TraceOption {
 	}
 }
 
+// WithAddEvictionListener if set to true, will allow spans on AddEvictionListener
+func WithAddEvictionListener(b bool) TraceOption {
+	return func(o *TraceOptions) {
+		o.AddEvictionListener = b
+	}
+}
+
+// WithCompareAndSwap if set to true, will allow spans on CompareAndSwap
+func WithCompareAndSwap(b bool) TraceOption {
+	return func(o *TraceOptions) {
+		o.CompareAndSwap = b
+	}
+}
+
 // WithDecrement if set to true, will allow spans on Decrement
 func WithDecrement(b bool) TraceOption {
 	return func(o *TraceOptions) {
@@ -278,6 +747,27 @@ func WithDeleteExpired(b bool) TraceOption {
 	}
 }
 
+// WithDeleteMatching if set to true, will allow spans on DeleteMatching
+func WithDeleteMatching(b bool) TraceOption {
+	return func(o *TraceOptions) {
+		o.DeleteMatching = b
+	}
+}
+
+// WithDeleteMulti if set to true, will allow a single span on DeleteMulti
+func WithDeleteMulti(b bool) TraceOption {
+	return func(o *TraceOptions) {
+		o.DeleteMulti = b
+	}
+}
+
+// WithDeletePrefix if set to true, will allow spans on DeletePrefix
+func WithDeletePrefix(b bool) TraceOption {
+	return func(o *TraceOptions) {
+		o.DeletePrefix = b
+	}
+}
+
 // WithFlush if set to true, will allow spans on Flush
 func WithFlush(b bool) TraceOption {
 	return func(o *TraceOptions) {
@@ -292,6 +782,27 @@ func WithGet(b bool) TraceOption {
 	}
 }
 
+// WithGetMulti if set to true, will allow a single span on GetMulti
+func WithGetMulti(b bool) TraceOption {
+	return func(o *TraceOptions) {
+		o.GetMulti = b
+	}
+}
+
+// WithGetOrLoad if set to true, will allow spans on GetOrLoad and its loader calls
+func WithGetOrLoad(b bool) TraceOption {
+	return func(o *TraceOptions) {
+		o.GetOrLoad = b
+	}
+}
+
+// WithGetVersioned if set to true, will allow spans on GetVersioned
+func WithGetVersioned(b bool) TraceOption {
+	return func(o *TraceOptions) {
+		o.GetVersioned = b
+	}
+}
+
 // WithGetWithExpiration if set to true, will allow spans on GetWithExpiration
 func WithGetWithExpiration(b bool) TraceOption {
 	return func(o *TraceOptions) {
@@ -404,6 +915,13 @@ func WithIncrementUintptr(b bool) TraceOption {
 	}
 }
 
+// WithInvalidateTag if set to true, will allow spans on InvalidateTag
+func WithInvalidateTag(b bool) TraceOption {
+	return func(o *TraceOptions) {
+		o.InvalidateTag = b
+	}
+}
+
 // WithItemCount if set to true, will allow spans on ItemCount
 func WithItemCount(b bool) TraceOption {
 	return func(o *TraceOptions) {
@@ -418,6 +936,13 @@ func WithItems(b bool) TraceOption {
 	}
 }
 
+// WithKeys if set to true, will allow spans on Keys
+func WithKeys(b bool) TraceOption {
+	return func(o *TraceOptions) {
+		o.Keys = b
+	}
+}
+
 // WithLoad if set to true, will allow spans on Load
 func WithLoad(b bool) TraceOption {
 	return func(o *TraceOptions) {
@@ -439,6 +964,27 @@ func WithOnEvicted(b bool) TraceOption {
 	}
 }
 
+// WithOnEvictedWithReason if set to true, will allow spans on OnEvictedWithReason
+func WithOnEvictedWithReason(b bool) TraceOption {
+	return func(o *TraceOptions) {
+		o.OnEvictedWithReason = b
+	}
+}
+
+// WithRefresh if set to true, will allow spans on background refreshes
+func WithRefresh(b bool) TraceOption {
+	return func(o *TraceOptions) {
+		o.Refresh = b
+	}
+}
+
+// WithRemoveEvictionListener if set to true, will allow spans on RemoveEvictionListener
+func WithRemoveEvictionListener(b bool) TraceOption {
+	return func(o *TraceOptions) {
+		o.RemoveEvictionListener = b
+	}
+}
+
 // WithReplace if set to true, will allow spans on Replace
 func WithReplace(b bool) TraceOption {
 	return func(o *TraceOptions) {
@@ -460,6 +1006,13 @@ func WithSaveFile(b bool) TraceOption {
 	}
 }
 
+// WithScan if set to true, will allow spans on Scan
+func WithScan(b bool) TraceOption {
+	return func(o *TraceOptions) {
+		o.Scan = b
+	}
+}
+
 // WithSet if set to true, will allow spans on Set
 func WithSet(b bool) TraceOption {
 	return func(o *TraceOptions) {
@@ -467,9 +1020,79 @@ func WithSet(b bool) TraceOption {
 	}
 }
 
+// WithSetAbsent if set to true, will allow spans on SetAbsent
+func WithSetAbsent(b bool) TraceOption {
+	return func(o *TraceOptions) {
+		o.SetAbsent = b
+	}
+}
+
 // WithSetDefault if set to true, will allow spans on SetDefault
 func WithSetDefault(b bool) TraceOption {
 	return func(o *TraceOptions) {
 		o.SetDefault = b
 	}
 }
+
+// WithSetIfVersion if set to true, will allow spans on SetIfVersion
+func WithSetIfVersion(b bool) TraceOption {
+	return func(o *TraceOptions) {
+		o.SetIfVersion = b
+	}
+}
+
+// WithSetMulti if set to true, will allow a single span on SetMulti
+func WithSetMulti(b bool) TraceOption {
+	return func(o *TraceOptions) {
+		o.SetMulti = b
+	}
+}
+
+// WithSetSliding if set to true, will allow spans on SetSliding
+func WithSetSliding(b bool) TraceOption {
+	return func(o *TraceOptions) {
+		o.SetSliding = b
+	}
+}
+
+// WithSetWithErr if set to true, will allow spans on SetWithErr
+func WithSetWithErr(b bool) TraceOption {
+	return func(o *TraceOptions) {
+		o.SetWithErr = b
+	}
+}
+
+// WithSetWithTags if set to true, will allow spans on SetWithTags
+func WithSetWithTags(b bool) TraceOption {
+	return func(o *TraceOptions) {
+		o.SetWithTags = b
+	}
+}
+
+// WithStoreCalls if set to true, will allow spans on the calls made to the Store
+func WithStoreCalls(b bool) TraceOption {
+	return func(o *TraceOptions) {
+		o.StoreCalls = b
+	}
+}
+
+// WithTouch if set to true, will allow spans on Touch
+func WithTouch(b bool) TraceOption {
+	return func(o *TraceOptions) {
+		o.Touch = b
+	}
+}
+
+// WithUpdate if set to true, will allow spans on Update
+func WithUpdate(b bool) TraceOption {
+	return func(o *TraceOptions) {
+		o.Update = b
+	}
+}
+
+// WithWatch if set to true, will allow spans on Watch
+func WithWatch(b bool) TraceOption {
+	return func(o *TraceOptions) {
+		o.Watch = b
+	}
+}
@@ -0,0 +1,186 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	octrace "go.opencensus.io/trace"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName identifies this package to OpenTelemetry tracer and meter providers
+const instrumentationName = "github.com/otternq/patrickmn-go-cache"
+
+// The following attribute keys are applied to metrics recorded through OpenTelemetry
+var (
+	otelNameKey     = attribute.Key("go_cache_name")
+	otelMethodKey   = attribute.Key("go_cache_method")
+	otelStatusKey   = attribute.Key("go_cache_status")
+	otelKeyspaceKey = attribute.Key("go_cache_keyspace")
+	otelReasonKey   = attribute.Key("go_cache_eviction_reason")
+)
+
+// The following gauges are observed from the last value sampled for each cache instance
+const (
+	otelItemCount = iota
+	otelBytes
+	otelStoreQueueDepth
+	otelGauges
+)
+
+// otelInstruments holds the OpenTelemetry instruments used in place of the OpenCensus measures
+type otelInstruments struct {
+	latency         metric.Float64Histogram
+	calls           metric.Int64Counter
+	lookups         metric.Int64Counter
+	staleServes     metric.Int64Counter
+	refreshFailures metric.Int64Counter
+	evictions       metric.Int64Counter
+	storeFailures   metric.Int64Counter
+	watchDrops      metric.Int64Counter
+
+	gauges       [otelGauges]metric.Int64ObservableGauge
+	registration metric.Registration
+
+	mu   sync.Mutex
+	last map[otelGaugeValue]int64
+}
+
+// otelGaugeValue identifies the last value of a gauge for a cache instance
+type otelGaugeValue struct {
+	gauge        int
+	instanceName string
+}
+
+func newOtelInstruments(mp metric.MeterProvider) (*otelInstruments, error) {
+	var (
+		meter = mp.Meter(instrumentationName)
+		i     = &otelInstruments{last: make(map[otelGaugeValue]int64)}
+		err   error
+	)
+
+	if i.latency, err = meter.Float64Histogram("go.cache.latency",
+		metric.WithDescription("The latency of calls in fractional milliseconds"),
+		metric.WithUnit("ms"),
+	); err != nil {
+		return nil, err
+	}
+	for _, c := range []struct {
+		counter     *metric.Int64Counter
+		name        string
+		description string
+	}{
+		{&i.calls, "go.cache.calls", "The number of various calls of methods"},
+		{&i.lookups, "go.cache.lookups", "The number of cache hits and misses"},
+		{&i.staleServes, "go.cache.stale_serves", "The number of stale items served while being refreshed"},
+		{&i.refreshFailures, "go.cache.refresh_failures", "The number of failed background refreshes"},
+		{&i.evictions, "go.cache.evictions", "The number of items evicted from the cache"},
+		{&i.storeFailures, "go.cache.store_failures", "The number of writes to the Store that failed, after exhausting retries when writing behind"},
+		{&i.watchDrops, "go.cache.watch_drops", "The number of events dropped for slow Watch subscribers"},
+	} {
+		if *c.counter, err = meter.Int64Counter(c.name, metric.WithDescription(c.description)); err != nil {
+			return nil, err
+		}
+	}
+	for gauge, g := range [otelGauges]struct {
+		name        string
+		description string
+		unit        string
+	}{
+		otelItemCount:       {"go.cache.item_count", "The number of items in the cache", ""},
+		otelBytes:           {"go.cache.bytes", "The estimated size of the items in the cache", "By"},
+		otelStoreQueueDepth: {"go.cache.store_queue_depth", "The number of writes queued for the Store", ""},
+	} {
+		if i.gauges[gauge], err = meter.Int64ObservableGauge(g.name,
+			metric.WithDescription(g.description),
+			metric.WithUnit(g.unit),
+		); err != nil {
+			return nil, err
+		}
+	}
+	if i.registration, err = meter.RegisterCallback(i.observe, i.gauges[otelItemCount], i.gauges[otelBytes], i.gauges[otelStoreQueueDepth]); err != nil {
+		return nil, err
+	}
+
+	return i, nil
+}
+
+// record records a call to method, the lookups it made are counted by their status
+func (i *otelInstruments) record(ctx context.Context, instanceName, method, status, keyspace string, timeSpent time.Duration, lookups map[string]int64) {
+	kvs := []attribute.KeyValue{
+		otelNameKey.String(instanceName),
+		otelMethodKey.String(method),
+		otelStatusKey.String(status),
+	}
+	if keyspace != "" {
+		kvs = append(kvs, otelKeyspaceKey.String(keyspace))
+	}
+	attrs := metric.WithAttributes(kvs...)
+
+	i.latency.Record(ctx, float64(timeSpent)/float64(time.Millisecond), attrs)
+	i.calls.Add(ctx, 1, attrs)
+	for lookupStatus, n := range lookups {
+		if n > 0 {
+			i.lookups.Add(ctx, n, metric.WithAttributes(append(kvs[:len(kvs):len(kvs)], otelStatusKey.String(lookupStatus))...))
+		}
+	}
+}
+
+// set keeps v as the last value of gauge for instanceName, it is reported on the next collection
+func (i *otelInstruments) set(gauge int, instanceName string, v int64) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.last[otelGaugeValue{gauge: gauge, instanceName: instanceName}] = v
+}
+
+func (i *otelInstruments) observe(ctx context.Context, o metric.Observer) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	for g, v := range i.last {
+		o.ObserveInt64(i.gauges[g.gauge], v, metric.WithAttributes(otelNameKey.String(g.instanceName)))
+	}
+	return nil
+}
+
+// close stops observing the gauges
+func (i *otelInstruments) close() {
+	_ = i.registration.Unregister()
+}
+
+// startOtelSpan starts an OpenTelemetry span, returning nil if no parent span exists and creating new spans is disabled
+func startOtelSpan(ctx context.Context, spanName string, options TraceOptions) (context.Context, *SpanWrapper) {
+	if !options.AllowRoot && !oteltrace.SpanContextFromContext(ctx).IsValid() {
+		return ctx, nil
+	}
+
+	var span oteltrace.Span
+	ctx, span = options.TracerProvider.Tracer(instrumentationName).Start(ctx, spanName,
+		oteltrace.WithSpanKind(oteltrace.SpanKindClient),
+		oteltrace.WithAttributes(otelAttributes(options.DefaultAttributes)...),
+	)
+
+	return ctx, &SpanWrapper{
+		otelSpan: span,
+	}
+}
+
+// otelAttributes converts OpenCensus attributes to their OpenTelemetry equivalent
+func otelAttributes(attrs []octrace.Attribute) []attribute.KeyValue {
+	kvs := make([]attribute.KeyValue, 0, len(attrs))
+	for _, attr := range attrs {
+		switch v := attr.Value().(type) {
+		case string:
+			kvs = append(kvs, attribute.String(attr.Key(), v))
+		case bool:
+			kvs = append(kvs, attribute.Bool(attr.Key(), v))
+		case int64:
+			kvs = append(kvs, attribute.Int64(attr.Key(), v))
+		case float64:
+			kvs = append(kvs, attribute.Float64(attr.Key(), v))
+		}
+	}
+	return kvs
+}
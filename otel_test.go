@@ -0,0 +1,160 @@
+package cache
+
+import (
+	"context"
+	"testing"
+
+	pgocache "github.com/patrickmn/go-cache"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestOpenTelemetry(t *testing.T) {
+	var (
+		recorder = tracetest.NewSpanRecorder()
+		tp       = sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+		reader   = sdkmetric.NewManualReader()
+		mp       = sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	)
+
+	tc := Wrap(pgocache.New(pgocache.DefaultExpiration, 0),
+		WithAllTraceOptions(),
+		WithInstanceName("otel"),
+		WithOpenTelemetry(tp, mp),
+	)
+
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "parent")
+	tc.Set(ctx, "a", 1, pgocache.DefaultExpiration)
+	tc.Get(ctx, "a")
+	tc.Get(ctx, "b")
+	if err := tc.Add(ctx, "a", 2, pgocache.DefaultExpiration); err == nil {
+		t.Fatal("expected an error adding an existing key")
+	}
+	parent.End()
+
+	spans := recorder.Ended()
+	names := make(map[string]sdktrace.ReadOnlySpan)
+	for _, span := range spans {
+		names[span.Name()] = span
+		if span.Name() != "parent" && span.Parent().SpanID() != parent.SpanContext().SpanID() {
+			t.Error(span.Name(), "is not parented under the calling span")
+		}
+	}
+	for _, name := range []string{"go.cache.set", "go.cache.get", "go.cache.add"} {
+		if _, ok := names[name]; !ok {
+			t.Error("no span recorded for", name)
+		}
+	}
+	if add, ok := names["go.cache.add"]; ok && add.Status().Code != codes.Error {
+		t.Error("expected go.cache.add span to have an error status, got:", add.Status())
+	}
+	if set, ok := names["go.cache.set"]; ok {
+		var instance string
+		for _, attr := range set.Attributes() {
+			if attr.Key == "cache.instance" {
+				instance = attr.Value.AsString()
+			}
+		}
+		if instance != "otel" {
+			t.Error("expected cache.instance attribute on span, got:", set.Attributes())
+		}
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatal("Error collecting metrics:", err)
+	}
+	lookups := map[string]int64{}
+	var sawLatency bool
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			switch m.Name {
+			case "go.cache.latency":
+				sawLatency = true
+			case "go.cache.lookups":
+				for _, dp := range m.Data.(metricdata.Sum[int64]).DataPoints {
+					status, _ := dp.Attributes.Value(attribute.Key("go_cache_status"))
+					lookups[status.AsString()] += dp.Value
+				}
+			}
+		}
+	}
+	if !sawLatency {
+		t.Error("no latency recorded")
+	}
+	if lookups[StatusFound] != 1 || lookups[StatusNotFound] != 1 {
+		t.Error("expected a hit and a miss, got:", lookups)
+	}
+}
+
+func TestOpenTelemetryWithoutParent(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	tc := Wrap(pgocache.New(pgocache.DefaultExpiration, 0), WithAllTraceOptions(), WithOpenTelemetry(tp, nil))
+	tc.Set(context.Background(), "a", 1, pgocache.DefaultExpiration)
+
+	if n := len(recorder.Ended()); n != 0 {
+		t.Error("expected no root spans, got:", n)
+	}
+}
+
+func TestOpenTelemetryMetrics(t *testing.T) {
+	var (
+		reader = sdkmetric.NewManualReader()
+		mp     = sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	)
+
+	tc := Wrap(pgocache.New(pgocache.DefaultExpiration, 0),
+		WithInstanceName("otel-metrics"),
+		WithOpenTelemetry(nil, mp),
+		WithWatchBuffer(1),
+	)
+	defer tc.Close(context.Background())
+
+	_, cancel := tc.Watch(context.Background(), "a")
+	defer cancel()
+	tc.Set(context.Background(), "a", 1, pgocache.DefaultExpiration)
+	tc.Set(context.Background(), "a", 2, pgocache.DefaultExpiration)
+	tc.Delete(context.Background(), "a")
+	tc.Set(context.Background(), "b", 1, pgocache.DefaultExpiration)
+	tc.sample(context.Background())
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatal("Error collecting metrics:", err)
+	}
+	values := map[string]int64{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			switch data := m.Data.(type) {
+			case metricdata.Sum[int64]:
+				for _, dp := range data.DataPoints {
+					values[m.Name] += dp.Value
+				}
+			case metricdata.Gauge[int64]:
+				for _, dp := range data.DataPoints {
+					if name, _ := dp.Attributes.Value(attribute.Key("go_cache_name")); name.AsString() == "otel-metrics" {
+						values[m.Name] = dp.Value
+					}
+				}
+			}
+		}
+	}
+	for name, want := range map[string]int64{
+		"go.cache.evictions":   2,
+		"go.cache.watch_drops": 2,
+		"go.cache.item_count":  1,
+	} {
+		if values[name] != want {
+			t.Errorf("expected %s to be %d, got %d", name, want, values[name])
+		}
+	}
+	if _, ok := values["go.cache.bytes"]; !ok {
+		t.Error("expected the bytes gauge to be observed, got:", values)
+	}
+}
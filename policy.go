@@ -0,0 +1,196 @@
+package cache
+
+import (
+	"container/heap"
+	"container/list"
+	"math/rand"
+)
+
+// EvictionPolicy tracks the keys held by a bounded Wrapper and chooses which key to evict when it is over capacity.
+// Implementations do not need to be safe for concurrent use, the Wrapper serializes calls.
+type EvictionPolicy interface {
+	// Added records that k was stored in the cache
+	Added(k string)
+
+	// Accessed records that k was read from the cache
+	Accessed(k string)
+
+	// Removed records that k left the cache
+	Removed(k string)
+
+	// Victim removes and returns the key that should be evicted next, false is returned if no keys are tracked
+	Victim() (string, bool)
+}
+
+// NewLRUPolicy returns an EvictionPolicy that evicts the least recently used key
+func NewLRUPolicy() EvictionPolicy {
+	return &lruPolicy{
+		order: list.New(),
+		keys:  make(map[string]*list.Element),
+	}
+}
+
+type lruPolicy struct {
+	order *list.List
+	keys  map[string]*list.Element
+}
+
+func (p *lruPolicy) Added(k string) {
+	if e, ok := p.keys[k]; ok {
+		p.order.MoveToFront(e)
+		return
+	}
+	p.keys[k] = p.order.PushFront(k)
+}
+
+func (p *lruPolicy) Accessed(k string) {
+	if e, ok := p.keys[k]; ok {
+		p.order.MoveToFront(e)
+	}
+}
+
+func (p *lruPolicy) Removed(k string) {
+	if e, ok := p.keys[k]; ok {
+		p.order.Remove(e)
+		delete(p.keys, k)
+	}
+}
+
+func (p *lruPolicy) Victim() (string, bool) {
+	e := p.order.Back()
+	if e == nil {
+		return "", false
+	}
+	k := p.order.Remove(e).(string)
+	delete(p.keys, k)
+	return k, true
+}
+
+// NewLFUPolicy returns an EvictionPolicy that evicts the least frequently used key, the oldest key is evicted first on ties
+func NewLFUPolicy() EvictionPolicy {
+	return &lfuPolicy{
+		keys: make(map[string]*lfuEntry),
+	}
+}
+
+type lfuEntry struct {
+	key   string
+	count uint64
+	seq   uint64
+	index int
+}
+
+// lfuHeap is a min-heap of entries ordered by use count
+type lfuHeap []*lfuEntry
+
+func (h lfuHeap) Len() int { return len(h) }
+
+func (h lfuHeap) Less(i, j int) bool {
+	if h[i].count == h[j].count {
+		return h[i].seq < h[j].seq
+	}
+	return h[i].count < h[j].count
+}
+
+func (h lfuHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *lfuHeap) Push(x interface{}) {
+	e := x.(*lfuEntry)
+	e.index = len(*h)
+	*h = append(*h, e)
+}
+
+func (h *lfuHeap) Pop() interface{} {
+	old := *h
+	e := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	return e
+}
+
+type lfuPolicy struct {
+	entries lfuHeap
+	keys    map[string]*lfuEntry
+	seq     uint64
+}
+
+func (p *lfuPolicy) Added(k string) {
+	if _, ok := p.keys[k]; ok {
+		p.Accessed(k)
+		return
+	}
+	p.seq++
+	e := &lfuEntry{key: k, count: 1, seq: p.seq}
+	heap.Push(&p.entries, e)
+	p.keys[k] = e
+}
+
+func (p *lfuPolicy) Accessed(k string) {
+	if e, ok := p.keys[k]; ok {
+		e.count++
+		heap.Fix(&p.entries, e.index)
+	}
+}
+
+func (p *lfuPolicy) Removed(k string) {
+	if e, ok := p.keys[k]; ok {
+		heap.Remove(&p.entries, e.index)
+		delete(p.keys, k)
+	}
+}
+
+func (p *lfuPolicy) Victim() (string, bool) {
+	if len(p.entries) == 0 {
+		return "", false
+	}
+	e := heap.Pop(&p.entries).(*lfuEntry)
+	delete(p.keys, e.key)
+	return e.key, true
+}
+
+// NewRandomPolicy returns an EvictionPolicy that evicts a random key
+func NewRandomPolicy() EvictionPolicy {
+	return &randomPolicy{
+		keys: make(map[string]int),
+	}
+}
+
+type randomPolicy struct {
+	order []string
+	keys  map[string]int
+}
+
+func (p *randomPolicy) Added(k string) {
+	if _, ok := p.keys[k]; ok {
+		return
+	}
+	p.keys[k] = len(p.order)
+	p.order = append(p.order, k)
+}
+
+func (p *randomPolicy) Accessed(k string) {}
+
+func (p *randomPolicy) Removed(k string) {
+	i, ok := p.keys[k]
+	if !ok {
+		return
+	}
+	last := len(p.order) - 1
+	p.order[i] = p.order[last]
+	p.keys[p.order[i]] = i
+	p.order = p.order[:last]
+	delete(p.keys, k)
+}
+
+func (p *randomPolicy) Victim() (string, bool) {
+	if len(p.order) == 0 {
+		return "", false
+	}
+	k := p.order[rand.Intn(len(p.order))]
+	p.Removed(k)
+	return k, true
+}
@@ -0,0 +1,85 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// KeyLoaderFunc loads the value for the given key along with the duration it should be cached for
+type KeyLoaderFunc func(ctx context.Context, k string) (interface{}, time.Duration, error)
+
+// revalidating reports whether stale-while-revalidate or refresh-ahead is enabled
+func (w *Wrapper) revalidating() bool {
+	return w.options.RefreshLoader != nil && (w.options.StaleTTL > 0 || w.options.RefreshAhead > 0)
+}
+
+// get looks up k, sliding its expiration and starting a background refresh when the item is stale or about to expire.
+// The returned expiration is the item's soft expiration, excluding any stale grace period.
+func (w *Wrapper) get(ctx context.Context, method string, k string) (v interface{}, exp time.Time, found bool) {
+	if v, exp, found = w.Cache.GetWithExpiration(k); !found {
+		return
+	}
+	exp, found = w.served(ctx, method, k, v, exp)
+	return
+}
+
+// served records the read of v, found under k with the expiration exp, as get does, and returns the soft expiration
+// of the item along with whether it is found. It may write to k, so the caller must not hold the lock on k.
+func (w *Wrapper) served(ctx context.Context, method string, k string, v interface{}, exp time.Time) (time.Time, bool) {
+	if IsAbsent(v) {
+		// negative entries are reported as misses carrying the Absent value
+		return exp, false
+	}
+	w.accessed(k)
+	exp = w.slide(k, exp)
+
+	if !w.revalidating() || exp.IsZero() {
+		return exp, true
+	}
+
+	exp = exp.Add(-w.options.StaleTTL)
+	now := time.Now()
+	switch {
+	case now.After(exp):
+		w.signal(ctx, Signal{Kind: SignalStaleServe, Method: method})
+		w.refresh(ctx, k)
+	case w.options.RefreshAhead > 0 && now.After(exp.Add(-w.options.RefreshAhead)):
+		w.refresh(ctx, k)
+	}
+
+	return exp, true
+}
+
+// refresh reloads k in the background. Refreshes share in-flight loads with GetOrLoad so a key is only ever
+// loaded once at a time.
+func (w *Wrapper) refresh(ctx context.Context, k string) {
+	// the refresh outlives the triggering call, so keep its span for parenting but drop its cancellation
+	ctx = detach(ctx)
+
+	w.loads.DoChan(k, func() (interface{}, error) {
+		return w.reload(ctx, k)
+	})
+}
+
+// reload runs the refresh loader for k and replaces the cached item with the result
+func (w *Wrapper) reload(ctx context.Context, k string) (v interface{}, err error) {
+	ctx, end := w.startKeyOp(ctx, "go.cache.refresh", w.options.Refresh, k, nil)
+	defer func() {
+		end(ErrorResult(err).WithValue(v))
+	}()
+
+	var d time.Duration
+	if v, d, err = w.options.RefreshLoader(ctx, k); errors.Is(err, ErrAbsent) {
+		// the item is gone from the source, so stop serving the stale copy
+		w.fill(ctx, k, Absent{}, w.negativeTTL())
+		return nil, ErrAbsent
+	} else if err != nil {
+		w.signal(ctx, Signal{Kind: SignalRefreshFailure})
+		return nil, err
+	}
+
+	w.fill(ctx, k, v, d)
+
+	return
+}
@@ -0,0 +1,96 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	pgocache "github.com/patrickmn/go-cache"
+)
+
+func TestStaleWhileRevalidate(t *testing.T) {
+	var calls int32
+	tc := Wrap(pgocache.New(pgocache.DefaultExpiration, 0),
+		WithAllTraceOptions(),
+		WithStaleTTL(time.Second),
+		WithRefreshLoader(func(ctx context.Context, k string) (interface{}, time.Duration, error) {
+			atomic.AddInt32(&calls, 1)
+			return "fresh", time.Minute, nil
+		}),
+	)
+
+	tc.Set(context.Background(), "a", "stale", 10*time.Millisecond)
+
+	<-time.After(20 * time.Millisecond)
+	x, exp, found := tc.GetWithExpiration(context.Background(), "a")
+	if !found {
+		t.Fatal("stale item was not served")
+	}
+	if x.(string) != "stale" {
+		t.Error("expected the stale value to be served, got:", x)
+	}
+	if !exp.Before(time.Now()) {
+		t.Error("expected the soft expiration to be reported, got:", exp)
+	}
+
+	<-time.After(20 * time.Millisecond)
+	x, found = tc.Get(context.Background(), "a")
+	if !found || x.(string) != "fresh" {
+		t.Error("item was not refreshed:", x)
+	}
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Error("refresh loader was called", n, "times, expected 1")
+	}
+}
+
+func TestRefreshAhead(t *testing.T) {
+	refreshed := make(chan struct{})
+	tc := Wrap(pgocache.New(pgocache.DefaultExpiration, 0),
+		WithRefreshAhead(time.Minute),
+		WithRefreshLoader(func(ctx context.Context, k string) (interface{}, time.Duration, error) {
+			defer close(refreshed)
+			return 2, time.Hour, nil
+		}),
+	)
+
+	tc.Set(context.Background(), "a", 1, 30*time.Second)
+
+	x, found := tc.Get(context.Background(), "a")
+	if !found || x.(int) != 1 {
+		t.Error("expected the current value to be served, got:", x)
+	}
+
+	select {
+	case <-refreshed:
+	case <-time.After(time.Second):
+		t.Fatal("item was not refreshed ahead of expiration")
+	}
+	<-time.After(10 * time.Millisecond)
+
+	x, found = tc.Get(context.Background(), "a")
+	if !found || x.(int) != 2 {
+		t.Error("item was not replaced by the refresh:", x)
+	}
+}
+
+func TestRefreshFailureKeepsStaleValue(t *testing.T) {
+	tc := Wrap(pgocache.New(pgocache.DefaultExpiration, 0),
+		WithStaleTTL(time.Second),
+		WithRefreshLoader(func(ctx context.Context, k string) (interface{}, time.Duration, error) {
+			return nil, 0, errors.New("backend unavailable")
+		}),
+	)
+
+	tc.Set(context.Background(), "a", 1, 10*time.Millisecond)
+
+	<-time.After(20 * time.Millisecond)
+	for i := 0; i < 2; i++ {
+		x, found := tc.Get(context.Background(), "a")
+		if !found || x.(int) != 1 {
+			t.Error("expected the stale value to be served, got:", x)
+		}
+		<-time.After(10 * time.Millisecond)
+	}
+}
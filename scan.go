@@ -0,0 +1,150 @@
+package cache
+
+import (
+	"container/heap"
+	"context"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Keys returns the sorted keys of the unexpired items starting with prefix.
+// Every call copies and walks all items of the cache and sorts the keys it returns, O(N log N) for N items, it is
+// meant for administration and debugging rather than request paths.
+func (w *Wrapper) Keys(ctx context.Context, prefix string) (keys []string) {
+	var examined int
+	ctx, end := w.startOp(ctx, "go.cache.keys", w.options.Keys)
+	defer func() {
+		end(CalledResult().WithScan(examined, 0))
+	}()
+
+	for k := range w.items() {
+		examined++
+		if strings.HasPrefix(k, prefix) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	return
+}
+
+// Scan returns up to limit sorted keys of the unexpired items matching glob, as understood by path.Match, that sort
+// after cursor. Pass an empty cursor to start a scan and the returned next cursor to continue it, next is empty once
+// the scan is complete. A limit of 0 or less returns every remaining key.
+// Every page copies and walks all items of the cache, keeping the limit smallest matching keys, O(N log limit) for
+// N items. Scanning the whole cache a page at a time is thus quadratic, prefer Keys or a larger limit for full scans.
+func (w *Wrapper) Scan(ctx context.Context, glob, cursor string, limit int) (keys []string, next string, err error) {
+	var examined int
+	ctx, end := w.startOp(ctx, "go.cache.scan", w.options.Scan)
+	defer func() {
+		end(ErrorResult(err).WithScan(examined, 0))
+	}()
+
+	if _, err = path.Match(glob, ""); err != nil {
+		return nil, "", err
+	}
+
+	page := keyHeap{limit: limit}
+	for k := range w.items() {
+		examined++
+		if cursor != "" && k <= cursor {
+			continue
+		}
+		if ok, _ := path.Match(glob, k); ok {
+			page.offer(k)
+		}
+	}
+	keys = page.keys
+	sort.Strings(keys)
+
+	if page.truncated {
+		next = keys[len(keys)-1]
+	}
+
+	return
+}
+
+// keyHeap keeps the limit smallest keys offered to it in a max-heap, or every key for a limit of 0 or less
+type keyHeap struct {
+	keys      []string
+	limit     int
+	truncated bool
+}
+
+// offer adds k, dropping the largest key once the heap holds more than limit keys
+func (h *keyHeap) offer(k string) {
+	switch {
+	case h.limit <= 0:
+		h.keys = append(h.keys, k)
+	case len(h.keys) < h.limit:
+		heap.Push(h, k)
+	default:
+		h.truncated = true
+		if k < h.keys[0] {
+			h.keys[0] = k
+			heap.Fix(h, 0)
+		}
+	}
+}
+
+func (h *keyHeap) Len() int           { return len(h.keys) }
+func (h *keyHeap) Less(i, j int) bool { return h.keys[i] > h.keys[j] }
+func (h *keyHeap) Swap(i, j int)      { h.keys[i], h.keys[j] = h.keys[j], h.keys[i] }
+
+func (h *keyHeap) Push(x interface{}) {
+	h.keys = append(h.keys, x.(string))
+}
+
+func (h *keyHeap) Pop() interface{} {
+	k := h.keys[len(h.keys)-1]
+	h.keys = h.keys[:len(h.keys)-1]
+	return k
+}
+
+// DeletePrefix deletes the items whose keys start with prefix and returns the number of items deleted
+func (w *Wrapper) DeletePrefix(ctx context.Context, prefix string) (n int) {
+	var examined int
+	ctx, end := w.startOp(ctx, "go.cache.deleteprefix", w.options.DeletePrefix)
+	defer func() {
+		end(CalledResult().WithScan(examined, n))
+	}()
+
+	// keys known to be absent are deleted as well, but only items are counted
+	for k, item := range w.entries() {
+		examined++
+		if strings.HasPrefix(k, prefix) {
+			if removed, _ := w.remove(ctx, w.key(k), EvictionReasonDeleted); removed && !IsAbsent(item.Object) {
+				n++
+			}
+		}
+	}
+
+	return
+}
+
+// DeleteMatching deletes the items whose keys match pattern, as understood by path.Match, and returns the number of
+// items deleted. An error is returned for a malformed pattern.
+func (w *Wrapper) DeleteMatching(ctx context.Context, pattern string) (n int, err error) {
+	var examined int
+	ctx, end := w.startOp(ctx, "go.cache.deletematching", w.options.DeleteMatching)
+	defer func() {
+		end(ErrorResult(err).WithScan(examined, n))
+	}()
+
+	if _, err = path.Match(pattern, ""); err != nil {
+		return 0, err
+	}
+
+	// keys known to be absent are deleted as well, but only items are counted
+	for k, item := range w.entries() {
+		examined++
+		if ok, _ := path.Match(pattern, k); ok {
+			if removed, _ := w.remove(ctx, w.key(k), EvictionReasonDeleted); removed && !IsAbsent(item.Object) {
+				n++
+			}
+		}
+	}
+
+	return
+}
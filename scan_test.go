@@ -0,0 +1,175 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+
+	pgocache "github.com/patrickmn/go-cache"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func scanCache(options ...TraceOption) *Wrapper {
+	tc := Wrap(pgocache.New(pgocache.DefaultExpiration, 0), options...)
+	for _, k := range []string{"user:1", "user:2", "user:3", "session:1", "flag"} {
+		tc.Set(context.Background(), k, k, pgocache.DefaultExpiration)
+	}
+	return tc
+}
+
+func TestKeys(t *testing.T) {
+	tc := scanCache()
+
+	keys := tc.Keys(context.Background(), "user:")
+	if len(keys) != 3 || keys[0] != "user:1" || keys[2] != "user:3" {
+		t.Error("unexpected keys:", keys)
+	}
+	if keys := tc.Keys(context.Background(), ""); len(keys) != 5 {
+		t.Error("expected every key for an empty prefix, got:", keys)
+	}
+}
+
+func TestScan(t *testing.T) {
+	tc := scanCache()
+
+	var (
+		all    []string
+		cursor string
+	)
+	for {
+		keys, next, err := tc.Scan(context.Background(), "user:*", cursor, 2)
+		if err != nil {
+			t.Fatal("Error scanning:", err)
+		}
+		all = append(all, keys...)
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+	if len(all) != 3 || all[0] != "user:1" || all[1] != "user:2" || all[2] != "user:3" {
+		t.Error("unexpected scan:", all)
+	}
+
+	if _, _, err := tc.Scan(context.Background(), "[", "", 0); err == nil {
+		t.Error("expected an error for a malformed pattern")
+	}
+}
+
+func TestScanPages(t *testing.T) {
+	tc := Wrap(pgocache.New(pgocache.DefaultExpiration, 0))
+	var want []string
+	for i := 0; i < 100; i++ {
+		k := fmt.Sprintf("k%03d", (i*37)%100)
+		tc.Set(context.Background(), k, i, pgocache.DefaultExpiration)
+		want = append(want, k)
+	}
+	sort.Strings(want)
+
+	var (
+		all    []string
+		cursor string
+	)
+	for {
+		keys, next, err := tc.Scan(context.Background(), "*", cursor, 10)
+		if err != nil {
+			t.Fatal("Error scanning:", err)
+		}
+		if len(keys) > 10 {
+			t.Fatal("expected at most 10 keys per page, got:", len(keys))
+		}
+		all = append(all, keys...)
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+	if fmt.Sprint(all) != fmt.Sprint(want) {
+		t.Error("expected every key once in order, got:", all)
+	}
+}
+
+func TestDeletePrefixAndMatching(t *testing.T) {
+	tc := scanCache()
+
+	if n := tc.DeletePrefix(context.Background(), "user:"); n != 3 {
+		t.Error("expected 3 keys to be deleted, got:", n)
+	}
+	if n, err := tc.DeleteMatching(context.Background(), "*:1"); err != nil || n != 1 {
+		t.Error("expected session:1 to be deleted, got:", n, err)
+	}
+	if keys := tc.Keys(context.Background(), ""); len(keys) != 1 || keys[0] != "flag" {
+		t.Error("unexpected keys left:", keys)
+	}
+	if _, err := tc.DeleteMatching(context.Background(), "["); err == nil {
+		t.Error("expected an error for a malformed pattern")
+	}
+}
+
+func TestScanNamespace(t *testing.T) {
+	tc := scanCache()
+	users := tc.Namespace("user")
+
+	if keys := users.Keys(context.Background(), ""); len(keys) != 3 || keys[0] != "1" {
+		t.Error("expected the namespace keys unprefixed, got:", keys)
+	}
+	if n := users.DeletePrefix(context.Background(), "1"); n != 1 {
+		t.Error("expected 1 key to be deleted, got:", n)
+	}
+	if _, found := tc.Get(context.Background(), "user:1"); found {
+		t.Error("expected user:1 to be deleted")
+	}
+}
+
+func TestScanSpan(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	tc := scanCache(WithDeletePrefix(true), WithOpenTelemetry(tp, nil))
+
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "parent")
+	tc.DeletePrefix(ctx, "user:")
+	parent.End()
+
+	for _, span := range recorder.Ended() {
+		if span.Name() != "go.cache.deleteprefix" {
+			continue
+		}
+		attrs := map[attribute.Key]int64{}
+		for _, attr := range span.Attributes() {
+			attrs[attr.Key] = attr.Value.AsInt64()
+		}
+		if attrs["cache.keys_examined"] != 5 || attrs["cache.keys_removed"] != 3 {
+			t.Error("unexpected scan attributes:", span.Attributes())
+		}
+		return
+	}
+	t.Error("no span recorded for go.cache.deleteprefix")
+}
+
+func BenchmarkKeys(b *testing.B) {
+	tc := benchmarkScanCache(10000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tc.Keys(context.Background(), "user:")
+	}
+}
+
+func BenchmarkScan(b *testing.B) {
+	tc := benchmarkScanCache(10000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tc.Scan(context.Background(), "user:*", "", 100)
+	}
+}
+
+func benchmarkScanCache(n int) *Wrapper {
+	tc := Wrap(pgocache.New(pgocache.DefaultExpiration, 0))
+	for i := 0; i < n; i++ {
+		tc.Set(context.Background(), fmt.Sprintf("user:%d", i), i, pgocache.DefaultExpiration)
+	}
+	return tc
+}
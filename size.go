@@ -0,0 +1,98 @@
+package cache
+
+import (
+	"reflect"
+	"sync"
+	"unsafe"
+)
+
+// maxSizeDepth bounds how far estimateSize follows pointers and nested values
+const maxSizeDepth = 8
+
+// estimateSize approximates the number of bytes retained by x.
+// It is intended for bookkeeping, not as an exact accounting of memory usage.
+func estimateSize(x interface{}) int64 {
+	switch v := x.(type) {
+	case nil:
+		return 0
+	case string:
+		return int64(unsafe.Sizeof(v)) + int64(len(v))
+	case []byte:
+		return int64(unsafe.Sizeof(v)) + int64(cap(v))
+	}
+	return sizeOf(reflect.ValueOf(x), 0)
+}
+
+func sizeOf(v reflect.Value, depth int) int64 {
+	if !v.IsValid() {
+		return 0
+	}
+	size := int64(v.Type().Size())
+	if depth >= maxSizeDepth || flat(v.Type()) {
+		return size
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		size += int64(v.Len())
+	case reflect.Ptr, reflect.Interface:
+		if !v.IsNil() {
+			size += sizeOf(v.Elem(), depth+1)
+		}
+	case reflect.Slice:
+		if elem := v.Type().Elem(); flat(elem) {
+			// byte and numeric slices are sized from their capacity rather than element by element
+			size += int64(v.Cap()) * int64(elem.Size())
+			break
+		}
+		for i := 0; i < v.Len(); i++ {
+			size += sizeOf(v.Index(i), depth+1)
+		}
+	case reflect.Array:
+		size = 0
+		for i := 0; i < v.Len(); i++ {
+			size += sizeOf(v.Index(i), depth+1)
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			size += sizeOf(iter.Key(), depth+1) + sizeOf(iter.Value(), depth+1)
+		}
+	case reflect.Struct:
+		size = 0
+		for i := 0; i < v.NumField(); i++ {
+			size += sizeOf(v.Field(i), depth+1)
+		}
+	}
+
+	return size
+}
+
+// flatTypes caches the result of flat by type
+var flatTypes sync.Map
+
+// flat reports whether values of t reference no other memory, such as numbers and arrays or structs of them, so
+// that their size is that of t
+func flat(t reflect.Type) bool {
+	if f, ok := flatTypes.Load(t); ok {
+		return f.(bool)
+	}
+
+	var f bool
+	switch t.Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		f = true
+	case reflect.Array:
+		f = flat(t.Elem())
+	case reflect.Struct:
+		f = true
+		for i := 0; i < t.NumField() && f; i++ {
+			f = flat(t.Field(i).Type)
+		}
+	}
+	flatTypes.Store(t, f)
+
+	return f
+}
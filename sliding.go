@@ -0,0 +1,123 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	pgocache "github.com/patrickmn/go-cache"
+)
+
+// ErrNotFound is returned by Touch when no item is stored under the key
+var ErrNotFound = errors.New("cache: item not found")
+
+// slidingKeys holds the sliding duration of the items stored by SetSliding
+type slidingKeys struct {
+	mu   sync.RWMutex
+	keys map[string]time.Duration
+}
+
+func newSlidingKeys() *slidingKeys {
+	return &slidingKeys{
+		keys: make(map[string]time.Duration),
+	}
+}
+
+func (s *slidingKeys) set(k string, d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys[k] = d
+}
+
+func (s *slidingKeys) get(k string) (time.Duration, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	d, ok := s.keys[k]
+	return d, ok
+}
+
+func (s *slidingKeys) remove(k string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.keys, k)
+}
+
+func (s *slidingKeys) reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys = make(map[string]time.Duration)
+}
+
+// SetSliding stores x under k as Set does, with an expiration that every read pushes back out to d from now.
+// The sliding duration is kept when k is overwritten by other writes and dropped once the item leaves the cache.
+// Items stored with DefaultExpiration only slide when the default is set by WithDefaultExpiration.
+func (w *Wrapper) SetSliding(ctx context.Context, k string, x interface{}, d time.Duration) {
+	var err error
+	ctx, end := w.startKeyOp(ctx, "go.cache.setsliding", w.options.SetSliding, k, x)
+	defer func() {
+		end(w.storedResult(writeResult(err), nil, d))
+	}()
+
+	k = w.key(k)
+	if d == pgocache.DefaultExpiration {
+		d = w.options.DefaultExpiration
+	}
+	if d > 0 {
+		w.sliding.set(k, d)
+	} else {
+		w.sliding.remove(k)
+	}
+	err = w.set(ctx, k, x, d)
+}
+
+// Touch sets the item stored under k to expire d from now without changing it, returning ErrNotFound if k is missing
+// or known to be absent
+func (w *Wrapper) Touch(ctx context.Context, k string, d time.Duration) (err error) {
+	ctx, end := w.startKeyOp(ctx, "go.cache.touch", w.options.Touch, k, nil)
+	defer func() {
+		end(w.storedResult(ErrorResult(err), err, d))
+	}()
+
+	k = w.key(k)
+	err = w.touch(k, d)
+
+	return
+}
+
+// touch stores the item under k again for d
+func (w *Wrapper) touch(k string, d time.Duration) error {
+	unlock := w.locks.lock(k)
+	defer unlock()
+
+	x, found := w.Cache.Get(k)
+	if !found || IsAbsent(x) {
+		return ErrNotFound
+	}
+	w.Cache.Set(k, x, w.expiration(d))
+
+	return nil
+}
+
+// slide extends the expiration exp of the item read under k to its sliding duration from now, returning the new
+// expiration. Items stored by SetSliding slide by their own duration, other items by SlidingTTL. Expirations are
+// only ever extended, and items that never expire are left alone.
+func (w *Wrapper) slide(k string, exp time.Time) time.Time {
+	if exp.IsZero() {
+		return exp
+	}
+	d, ok := w.sliding.get(k)
+	if !ok {
+		d = w.options.SlidingTTL
+	}
+	if d <= 0 {
+		return exp
+	}
+
+	slid := time.Now().Add(w.expiration(d))
+	if !slid.After(exp) || w.touch(k, d) != nil {
+		// the item already expires later, or it left the cache since it was read
+		return exp
+	}
+	return slid
+}
@@ -0,0 +1,84 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	pgocache "github.com/patrickmn/go-cache"
+)
+
+func TestSetSliding(t *testing.T) {
+	tc := Wrap(pgocache.New(pgocache.DefaultExpiration, 0))
+
+	tc.SetSliding(context.Background(), "a", 1, 100*time.Millisecond)
+	for i := 0; i < 6; i++ {
+		time.Sleep(50 * time.Millisecond)
+		if _, found := tc.Get(context.Background(), "a"); !found {
+			t.Fatal("expected reads to keep the item alive")
+		}
+	}
+
+	time.Sleep(150 * time.Millisecond)
+	if _, found := tc.Get(context.Background(), "a"); found {
+		t.Error("expected the item to expire once it stopped being read")
+	}
+
+	tc.Namespace("ns").SetSliding(context.Background(), "b", 1, time.Minute)
+	tc.Delete(context.Background(), "ns:b")
+	if _, ok := tc.sliding.get("ns:b"); ok {
+		t.Error("expected the sliding duration to be dropped with the item")
+	}
+}
+
+func TestSlidingTTL(t *testing.T) {
+	tc := Wrap(pgocache.New(pgocache.DefaultExpiration, 0), WithSlidingTTL(time.Minute))
+
+	tc.Set(context.Background(), "short", 1, time.Second)
+	if _, exp, _ := tc.GetWithExpiration(context.Background(), "short"); time.Until(exp) < 59*time.Second {
+		t.Error("expected the read to extend the expiration to SlidingTTL, got:", exp)
+	}
+
+	tc.Set(context.Background(), "long", 1, time.Hour)
+	if _, exp, _ := tc.GetWithExpiration(context.Background(), "long"); time.Until(exp) < 59*time.Minute {
+		t.Error("expected reads never to shorten the expiration, got:", exp)
+	}
+
+	tc.Set(context.Background(), "forever", 1, pgocache.NoExpiration)
+	if _, exp, _ := tc.GetWithExpiration(context.Background(), "forever"); !exp.IsZero() {
+		t.Error("expected items that never expire to be left alone, got:", exp)
+	}
+
+	tc.SetSliding(context.Background(), "own", 1, time.Hour)
+	tc.Set(context.Background(), "own", 2, time.Second)
+	if _, exp, _ := tc.GetWithExpiration(context.Background(), "own"); time.Until(exp) < 59*time.Minute {
+		t.Error("expected items stored by SetSliding to slide by their own duration, got:", exp)
+	}
+}
+
+func TestTouch(t *testing.T) {
+	r := &recordingInstrumenter{}
+	tc := Wrap(pgocache.New(pgocache.DefaultExpiration, 0), WithInstrumenter(r))
+
+	if err := tc.Touch(context.Background(), "a", time.Minute); !errors.Is(err, ErrNotFound) {
+		t.Error("expected ErrNotFound for a missing key, got:", err)
+	}
+
+	tc.Set(context.Background(), "a", 1, time.Second)
+	if err := tc.Touch(context.Background(), "a", time.Hour); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	x, exp, found := tc.GetWithExpiration(context.Background(), "a")
+	if !found || x.(int) != 1 || time.Until(exp) < 59*time.Minute {
+		t.Error("expected Touch to extend the item without changing it, got:", x, exp)
+	}
+
+	ops := r.results()
+	if ops[0].res.Status != StatusError || ops[2].res.Status != StatusOK || ops[2].op.Method != "go.cache.touch" {
+		t.Error("expected Touch calls to be instrumented, got:", ops)
+	}
+	if d := ops[2].res.Expiration.Sub(exp); d < -time.Second || d > time.Second {
+		t.Error("expected the Touch result to report the new expiration, got:", ops[2].res.Expiration)
+	}
+}
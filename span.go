@@ -4,49 +4,85 @@ import (
 	"context"
 
 	"go.opencensus.io/trace"
+	"go.opentelemetry.io/otel/codes"
+	oteltrace "go.opentelemetry.io/otel/trace"
 )
 
 // SpanWrapper holds a pointer to a span that allows us to call a function to close the span
 type SpanWrapper struct {
-	span *trace.Span
+	span     *trace.Span
+	otelSpan oteltrace.Span
 }
 
 // AllowTrace checks to see if we should start a trace on the given function call
 func AllowTrace(ctx context.Context, allow, root bool) bool {
-	return allow && (root || trace.FromContext(ctx) != nil)
+	return allow && (root || trace.FromContext(ctx) != nil || oteltrace.SpanContextFromContext(ctx).IsValid())
 }
 
-// StartSpan creates a span on the given call and returns a SpanWrapper. SpanWrapper will be nil if no parentSpan exists and creating new spans is disabled
-func StartSpan(ctx context.Context, spanName string, options TraceOptions) *SpanWrapper {
+// StartSpan creates a span on the given call and returns the derived context along with a SpanWrapper.
+// The returned context carries the new span so that work done on behalf of the call is parented under it.
+// An OpenTelemetry span is created instead of an OpenCensus one when options has a TracerProvider.
+// SpanWrapper will be nil, and the supplied context returned unchanged, if no parentSpan exists and creating new spans is disabled
+func StartSpan(ctx context.Context, spanName string, options TraceOptions) (context.Context, *SpanWrapper) {
+	if options.TracerProvider != nil {
+		return startOtelSpan(ctx, spanName, options)
+	}
+
 	parentSpan := trace.FromContext(ctx)
 	if !options.AllowRoot && parentSpan == nil {
-		return nil
+		return ctx, nil
 	}
 	var span *trace.Span
-	_, span = trace.StartSpan(ctx, spanName,
+	ctx, span = trace.StartSpan(ctx, spanName,
 		trace.WithSpanKind(trace.SpanKindClient),
 		trace.WithSampler(options.Sampler),
 	)
 	if len(options.DefaultAttributes) > 0 {
 		span.AddAttributes(options.DefaultAttributes...)
 	}
-	return &SpanWrapper{
+	return ctx, &SpanWrapper{
 		span: span,
 	}
 }
 
+// addAttributes sets attrs on the span
+func (s *SpanWrapper) addAttributes(attrs ...trace.Attribute) {
+	if len(attrs) == 0 {
+		return
+	}
+	if s.otelSpan != nil {
+		s.otelSpan.SetAttributes(otelAttributes(attrs)...)
+		return
+	}
+	s.span.AddAttributes(attrs...)
+}
+
 // EndSpanWithErr sets the status of the span based on the supplied error and then ends the span
 func (s *SpanWrapper) EndSpanWithErr(err error) {
 	s.setSpanStatus(err)
-	s.span.End()
+	s.EndSpan()
 }
 
 // EndSpan sets the status of the span and then ends the span
 func (s *SpanWrapper) EndSpan() {
+	if s.otelSpan != nil {
+		s.otelSpan.End()
+		return
+	}
 	s.span.End()
 }
 
 func (s *SpanWrapper) setSpanStatus(err error) {
+	if s.otelSpan != nil {
+		if err == nil {
+			s.otelSpan.SetStatus(codes.Ok, "")
+		} else {
+			s.otelSpan.RecordError(err)
+			s.otelSpan.SetStatus(codes.Error, err.Error())
+		}
+		return
+	}
+
 	var status trace.Status
 	if err == nil {
 		status.Code = trace.StatusCodeOK
@@ -56,3 +92,12 @@ func (s *SpanWrapper) setSpanStatus(err error) {
 	}
 	s.span.SetStatus(status)
 }
+
+// detach returns a background context carrying the spans of ctx, for work that outlives the call that started it
+func detach(ctx context.Context) context.Context {
+	detached := trace.NewContext(context.Background(), trace.FromContext(ctx))
+	if span := oteltrace.SpanFromContext(ctx); span.SpanContext().IsValid() {
+		detached = oteltrace.ContextWithSpan(detached, span)
+	}
+	return detached
+}
@@ -0,0 +1,34 @@
+package cache
+
+import (
+	"context"
+	"testing"
+
+	"go.opencensus.io/trace"
+)
+
+func TestStartSpanReturnsChildContext(t *testing.T) {
+	options := TraceOptions{AllowRoot: true, Sampler: trace.AlwaysSample()}
+
+	ctx, span := StartSpan(context.Background(), "go.cache.test", options)
+	if span == nil {
+		t.Fatal("expected a span when AllowRoot is set")
+	}
+	defer span.EndSpan()
+
+	if got := trace.FromContext(ctx); got != span.span {
+		t.Error("returned context does not carry the started span")
+	}
+}
+
+func TestStartSpanWithoutParent(t *testing.T) {
+	parent := context.Background()
+
+	ctx, span := StartSpan(parent, "go.cache.test", TraceOptions{})
+	if span != nil {
+		t.Error("expected no span without a parent when AllowRoot is unset")
+	}
+	if ctx != parent {
+		t.Error("expected the supplied context to be returned unchanged")
+	}
+}
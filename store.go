@@ -0,0 +1,335 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	pgocache "github.com/patrickmn/go-cache"
+)
+
+// The following defaults apply to writes behind when their options are not set
+const (
+	DefaultWriteBehindInterval  = time.Second
+	DefaultWriteBehindBatchSize = 100
+)
+
+// writeBehindQueue is the number of writes queued per batch before callers block
+const writeBehindQueue = 10
+
+// storeRetryBackoff is the delay before the first retry of a failed write behind, it doubles on each retry
+const storeRetryBackoff = 10 * time.Millisecond
+
+// Store persists the items of a Wrapper, see WithWriteThrough and WithWriteBehind
+type Store interface {
+	// Put stores v under k, d is the expiration it was cached with
+	Put(ctx context.Context, k string, v interface{}, d time.Duration) error
+
+	// Delete removes the item stored under k
+	Delete(ctx context.Context, k string) error
+
+	// Get returns the item stored under k, found is false if it is missing
+	Get(ctx context.Context, k string) (v interface{}, found bool, err error)
+}
+
+// SetWithErr stores x under k as Set does, returning the error of the Store in write through mode. Unlike Set, the
+// item is not cached if it could not be stored.
+func (w *Wrapper) SetWithErr(ctx context.Context, k string, x interface{}, d time.Duration) (err error) {
+	ctx, end := w.startKeyOp(ctx, "go.cache.setwitherr", w.options.SetWithErr, k, x)
+	defer func() {
+		end(w.storedResult(ErrorResult(err), err, d))
+	}()
+
+	k, d = w.key(k), w.jitter(d)
+	err = w.write(ctx, k, x, d, persistThenCache, nil)
+
+	return
+}
+
+// persist writes x under k to the Store, or queues the write when writing behind
+func (w *Wrapper) persist(ctx context.Context, k string, x interface{}, d time.Duration) error {
+	if w.options.Store == nil {
+		return nil
+	}
+	return w.writeStore(storeWrite{ctx: ctx, by: w, k: k, x: x, d: d})
+}
+
+// unpersist deletes k from the Store, or queues the delete when writing behind
+func (w *Wrapper) unpersist(ctx context.Context, k string) error {
+	if w.options.Store == nil {
+		return nil
+	}
+	return w.writeStore(storeWrite{ctx: ctx, by: w, k: k, delete: true})
+}
+
+// writeStore makes op in write through mode, recording its failure, and queues it when writing behind
+func (w *Wrapper) writeStore(op storeWrite) error {
+	if w.writer != nil {
+		w.writer.enqueue(op)
+		return nil
+	}
+	err := op.write()
+	if err != nil {
+		w.signal(op.ctx, Signal{Kind: SignalStoreFailure})
+	}
+	return err
+}
+
+// readThrough caches the item stored under k in the Store, reporting whether it was found. When writing behind, a
+// write of k still queued for the Store is newer than the Store, a queued delete reports k as missing.
+func (w *Wrapper) readThrough(ctx context.Context, k string) (found bool) {
+	if w.options.Store == nil {
+		return false
+	}
+	if w.writer != nil {
+		if op, ok := w.writer.pending(k); ok {
+			if op.delete {
+				return false
+			}
+			w.fill(ctx, k, op.x, pgocache.DefaultExpiration)
+			return true
+		}
+	}
+
+	var (
+		v   interface{}
+		err error
+	)
+	ctx, end := w.startKeyOp(ctx, "go.cache.store.get", w.options.StoreCalls, k, nil)
+	defer func() {
+		res := FoundResult(found).WithValue(v)
+		res.Err = err
+		end(res)
+	}()
+
+	if v, found, err = w.options.Store.Get(ctx, k); err != nil || !found {
+		return false
+	}
+	w.fill(ctx, k, v, pgocache.DefaultExpiration)
+
+	return true
+}
+
+// writeResult is the Result of write calls that only return an error from the Store
+func writeResult(err error) Result {
+	if err != nil {
+		return ErrorResult(err)
+	}
+	return CalledResult()
+}
+
+// storeWrite is a put or delete made to the Store on behalf of a Wrapper call
+type storeWrite struct {
+	// ctx is that of the originating call, the span of the write is a child of its span
+	ctx    context.Context
+	by     *Wrapper
+	k      string
+	x      interface{}
+	d      time.Duration
+	delete bool
+}
+
+// write makes the put or delete under its own span
+func (op storeWrite) write() (err error) {
+	method, x := "go.cache.store.put", op.x
+	if op.delete {
+		method, x = "go.cache.store.delete", nil
+	}
+	ctx, end := op.by.startKeyOp(op.ctx, method, op.by.options.StoreCalls, op.k, x)
+	defer func() {
+		end(ErrorResult(err))
+	}()
+
+	if op.delete {
+		return op.by.options.Store.Delete(ctx, op.k)
+	}
+	return op.by.options.Store.Put(ctx, op.k, op.x, op.d)
+}
+
+// writeBehind makes the writes queued for the Store in batches from a background goroutine
+type writeBehind struct {
+	w         *Wrapper
+	interval  time.Duration
+	batchSize int
+	limit     int
+	retries   int
+
+	// mu guards the queue, the batch being flushed and closed, space is signalled once queued writes are taken.
+	// Store writes are never made while holding mu.
+	mu       sync.Mutex
+	space    *sync.Cond
+	queue    []storeWrite
+	flushing []storeWrite
+	closed   bool
+
+	errMu sync.Mutex
+	err   error
+
+	// kick wakes the writer once a full batch is queued
+	kick      chan struct{}
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func (w *Wrapper) startWriteBehind() *writeBehind {
+	wb := &writeBehind{
+		w:         w,
+		interval:  w.options.WriteBehindInterval,
+		batchSize: w.options.WriteBehindBatchSize,
+		retries:   w.options.StoreRetries,
+		kick:      make(chan struct{}, 1),
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	if wb.interval <= 0 {
+		wb.interval = DefaultWriteBehindInterval
+	}
+	if wb.batchSize <= 0 {
+		wb.batchSize = DefaultWriteBehindBatchSize
+	}
+	wb.limit = wb.batchSize * writeBehindQueue
+	wb.space = sync.NewCond(&wb.mu)
+
+	go wb.run()
+
+	return wb
+}
+
+// enqueue queues op, blocking while the queue is full. Once the writer is closed op is written synchronously,
+// after the queued writes so that it is not overwritten by an older write of the same key.
+func (wb *writeBehind) enqueue(op storeWrite) {
+	// the write outlives the call, keep its span but not its cancellation
+	op.ctx = detach(op.ctx)
+
+	wb.mu.Lock()
+	for len(wb.queue) >= wb.limit && !wb.closed {
+		wb.space.Wait()
+	}
+	if wb.closed {
+		wb.mu.Unlock()
+		<-wb.done
+		wb.write(op)
+		return
+	}
+	wb.queue = append(wb.queue, op)
+	depth := len(wb.queue)
+	wb.mu.Unlock()
+
+	wb.w.signal(op.ctx, Signal{Kind: SignalStoreQueueDepth, Value: int64(depth)})
+	if depth >= wb.batchSize {
+		select {
+		case wb.kick <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// take removes the queued writes to flush them and wakes the callers waiting for space
+func (wb *writeBehind) take() []storeWrite {
+	wb.mu.Lock()
+	defer wb.mu.Unlock()
+
+	batch := wb.queue
+	wb.queue = nil
+	wb.flushing = batch
+	wb.space.Broadcast()
+
+	return batch
+}
+
+// pending returns the last write of k that is queued or being flushed, ok is false if there is none
+func (wb *writeBehind) pending(k string) (op storeWrite, ok bool) {
+	wb.mu.Lock()
+	defer wb.mu.Unlock()
+
+	for _, ops := range [][]storeWrite{wb.queue, wb.flushing} {
+		for i := len(ops) - 1; i >= 0; i-- {
+			if ops[i].k == k {
+				return ops[i], true
+			}
+		}
+	}
+	return storeWrite{}, false
+}
+
+func (wb *writeBehind) depth() int {
+	wb.mu.Lock()
+	defer wb.mu.Unlock()
+	return len(wb.queue)
+}
+
+func (wb *writeBehind) run() {
+	defer close(wb.done)
+
+	ticker := time.NewTicker(wb.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-wb.kick:
+		case <-ticker.C:
+		case <-wb.stop:
+			wb.flush(wb.take())
+			return
+		}
+		wb.flush(wb.take())
+	}
+}
+
+// flush writes batch, keeping only the last write of each key
+func (wb *writeBehind) flush(batch []storeWrite) {
+	last := make(map[string]int, len(batch))
+	for i, op := range batch {
+		last[op.k] = i
+	}
+	for i, op := range batch {
+		if last[op.k] == i {
+			wb.write(op)
+		}
+	}
+
+	wb.mu.Lock()
+	wb.flushing = nil
+	wb.mu.Unlock()
+
+	wb.w.signal(context.Background(), Signal{Kind: SignalStoreQueueDepth, Value: int64(wb.depth())})
+}
+
+// write makes op, retrying failures with an exponential backoff
+func (wb *writeBehind) write(op storeWrite) {
+	backoff := storeRetryBackoff
+	for attempt := 0; ; attempt++ {
+		err := op.write()
+		if err == nil {
+			return
+		}
+		if attempt >= wb.retries {
+			op.by.signal(op.ctx, Signal{Kind: SignalStoreFailure})
+			wb.errMu.Lock()
+			wb.err = err
+			wb.errMu.Unlock()
+			return
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// close flushes the queued writes and stops the writer, returning the error of the last write that failed
+func (wb *writeBehind) close() error {
+	wb.closeOnce.Do(func() {
+		wb.mu.Lock()
+		wb.closed = true
+		wb.space.Broadcast()
+		wb.mu.Unlock()
+
+		close(wb.stop)
+	})
+
+	<-wb.done
+
+	wb.errMu.Lock()
+	defer wb.errMu.Unlock()
+	return wb.err
+}
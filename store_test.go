@@ -0,0 +1,305 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	pgocache "github.com/patrickmn/go-cache"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+type memoryStore struct {
+	mu       sync.Mutex
+	items    map[string]interface{}
+	puts     int
+	failures int
+	delay    time.Duration
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{items: make(map[string]interface{})}
+}
+
+func (s *memoryStore) Put(ctx context.Context, k string, v interface{}, d time.Duration) error {
+	time.Sleep(s.delay)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.puts++
+	if s.failures > 0 {
+		s.failures--
+		return errors.New("store unavailable")
+	}
+	s.items[k] = v
+	return nil
+}
+
+func (s *memoryStore) Delete(ctx context.Context, k string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.items, k)
+	return nil
+}
+
+func (s *memoryStore) Get(ctx context.Context, k string) (interface{}, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v, ok := s.items[k]
+	return v, ok, nil
+}
+
+func (s *memoryStore) get(k string) (interface{}, bool) {
+	v, ok, _ := s.Get(context.Background(), k)
+	return v, ok
+}
+
+func TestWriteThrough(t *testing.T) {
+	store := newMemoryStore()
+	tc := Wrap(pgocache.New(pgocache.DefaultExpiration, 0), WithAllTraceOptions(), WithWriteThrough(store))
+
+	if err := tc.SetWithErr(context.Background(), "a", 1, pgocache.DefaultExpiration); err != nil {
+		t.Fatal("Error setting a:", err)
+	}
+	if v, ok := store.get("a"); !ok || v != 1 {
+		t.Error("expected a to be written through, got:", v)
+	}
+
+	store.failures = 1
+	if err := tc.SetWithErr(context.Background(), "b", 1, pgocache.DefaultExpiration); err == nil {
+		t.Error("expected the store error to be returned")
+	}
+	if _, found := tc.Get(context.Background(), "b"); found {
+		t.Error("expected an item that could not be stored not to be cached")
+	}
+
+	tc.Delete(context.Background(), "a")
+	if _, ok := store.get("a"); ok {
+		t.Error("expected the delete to be written through")
+	}
+}
+
+func TestSetCachesOnStoreFailure(t *testing.T) {
+	store := newMemoryStore()
+	tc := Wrap(pgocache.New(pgocache.DefaultExpiration, 0), WithWriteThrough(store))
+
+	store.failures = 1
+	tc.Set(context.Background(), "a", 1, pgocache.DefaultExpiration)
+	if v, found := tc.Get(context.Background(), "a"); !found || v != 1 {
+		t.Error("expected Set to cache the item the Store failed to write, got:", v, found)
+	}
+	if _, ok := store.get("a"); ok {
+		t.Error("expected the failed write not to be stored")
+	}
+}
+
+func TestWriteThroughEveryWrite(t *testing.T) {
+	store := newMemoryStore()
+	tc := Wrap(pgocache.New(pgocache.DefaultExpiration, 0), WithWriteThrough(store))
+	ctx := context.Background()
+
+	tc.Add(ctx, "add", 1, pgocache.DefaultExpiration)
+	tc.Set(ctx, "replace", 1, pgocache.DefaultExpiration)
+	tc.Replace(ctx, "replace", 2, pgocache.DefaultExpiration)
+	tc.SetWithTags(ctx, "tagged", 1, pgocache.DefaultExpiration, "t")
+	tc.SetMulti(ctx, map[string]interface{}{"multi": 1}, pgocache.DefaultExpiration)
+	tc.SetSliding(ctx, "sliding", 1, time.Minute)
+	tc.Update(ctx, "update", func(old interface{}, found bool) (interface{}, time.Duration, error) {
+		return 1, pgocache.DefaultExpiration, nil
+	})
+	tc.Set(ctx, "cas", 1, pgocache.DefaultExpiration)
+	tc.CompareAndSwap(ctx, "cas", 1, 2)
+	tc.SetIfVersion(ctx, "version", 1, pgocache.DefaultExpiration, 0)
+	tc.Set(ctx, "counter", 1, pgocache.DefaultExpiration)
+	tc.IncrementInt(ctx, "counter", 2)
+
+	for k, want := range map[string]interface{}{
+		"add": 1, "replace": 2, "tagged": 1, "multi": 1, "sliding": 1, "update": 1, "cas": 2, "version": 1, "counter": 3,
+	} {
+		if v, ok := store.get(k); !ok || v != want {
+			t.Errorf("%s: expected %v to be written through, got %v", k, want, v)
+		}
+	}
+
+	tc.DeleteMulti(ctx, []string{"add"})
+	tc.DeletePrefix(ctx, "repl")
+	tc.DeleteMatching(ctx, "mul*")
+	tc.InvalidateTag(ctx, "t")
+	for _, k := range []string{"add", "replace", "multi", "tagged"} {
+		if _, ok := store.get(k); ok {
+			t.Errorf("%s: expected the delete to be written through", k)
+		}
+		if _, found := tc.Get(ctx, k); found {
+			t.Errorf("%s: expected the deleted item not to be read through again", k)
+		}
+	}
+}
+
+func TestReadThrough(t *testing.T) {
+	store := newMemoryStore()
+	store.items["a"] = 1
+	tc := Wrap(pgocache.New(pgocache.DefaultExpiration, 0), WithWriteThrough(store))
+
+	if v, found := tc.Get(context.Background(), "a"); !found || v != 1 {
+		t.Error("expected a miss to be read through, got:", v, found)
+	}
+	if _, found := tc.Cache.Get("a"); !found {
+		t.Error("expected the item read through to be cached")
+	}
+}
+
+func TestWriteBehind(t *testing.T) {
+	store := newMemoryStore()
+	tc := Wrap(pgocache.New(pgocache.DefaultExpiration, 0),
+		WithWriteBehind(store),
+		WithWriteBehindInterval(time.Hour),
+		WithStoreRetries(2),
+	)
+
+	store.failures = 1
+	for i := 0; i < 10; i++ {
+		tc.Set(context.Background(), "a", i, pgocache.DefaultExpiration)
+	}
+	tc.Set(context.Background(), "b", 1, pgocache.DefaultExpiration)
+	tc.Delete(context.Background(), "b")
+
+	if _, ok := store.get("a"); ok {
+		t.Error("expected writes to be queued until the batch is flushed")
+	}
+	if err := tc.Close(context.Background()); err != nil {
+		t.Fatal("Error closing:", err)
+	}
+
+	if v, ok := store.get("a"); !ok || v != 9 {
+		t.Error("expected the last write of a to be flushed on close, got:", v)
+	}
+	if _, ok := store.get("b"); ok {
+		t.Error("expected b to be deleted")
+	}
+	if store.puts != 2 {
+		t.Error("expected the writes of a to be coalesced and retried once, got puts:", store.puts)
+	}
+}
+
+func TestWriteBehindReadThrough(t *testing.T) {
+	store := newMemoryStore()
+	store.items["a"], store.items["b"] = 1, 1
+	tc := Wrap(pgocache.New(pgocache.DefaultExpiration, 0),
+		WithWriteBehind(store),
+		WithWriteBehindInterval(time.Hour),
+	)
+	defer tc.Close(context.Background())
+
+	tc.Delete(context.Background(), "a")
+	if v, found := tc.Get(context.Background(), "a"); found {
+		t.Error("expected a queued delete not to be read through, got:", v)
+	}
+
+	tc.Set(context.Background(), "b", 2, pgocache.DefaultExpiration)
+	tc.Cache.Delete("b")
+	if v, found := tc.Get(context.Background(), "b"); !found || v != 2 {
+		t.Error("expected a queued write to be read through, got:", v, found)
+	}
+}
+
+func TestWriteBehindQueueDepth(t *testing.T) {
+	r := &recordingInstrumenter{}
+	tc := Wrap(pgocache.New(pgocache.DefaultExpiration, 0),
+		WithInstrumenter(r),
+		WithWriteBehind(newMemoryStore()),
+		WithWriteBehindInterval(time.Hour),
+	)
+
+	tc.Set(context.Background(), "a", 1, pgocache.DefaultExpiration)
+	tc.Set(context.Background(), "b", 2, pgocache.DefaultExpiration)
+	tc.Close(context.Background())
+
+	var depths []int64
+	for _, s := range r.recorded() {
+		if s.Kind == SignalStoreQueueDepth {
+			depths = append(depths, s.Value)
+		}
+	}
+	if len(depths) != 3 || depths[0] != 1 || depths[1] != 2 || depths[2] != 0 {
+		t.Error("expected the queue depth to be recorded as writes are queued and flushed, got:", depths)
+	}
+}
+
+func TestWriteBehindSlowStore(t *testing.T) {
+	store := newMemoryStore()
+	store.failures = 1 << 30
+	store.delay = time.Millisecond
+	tc := Wrap(pgocache.New(pgocache.DefaultExpiration, 0),
+		WithWriteBehind(store),
+		WithWriteBehindBatchSize(1),
+		WithWriteBehindInterval(time.Millisecond),
+	)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		var wg sync.WaitGroup
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				for j := 0; j < 20; j++ {
+					tc.Set(context.Background(), fmt.Sprintf("%d:%d", i, j), j, pgocache.DefaultExpiration)
+				}
+			}(i)
+		}
+		wg.Wait()
+		if err := tc.Close(context.Background()); err == nil {
+			t.Error("expected the failed writes to be reported on close")
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("expected writers blocked on a full queue to make progress while writes fail")
+	}
+}
+
+func TestWriteBehindFailure(t *testing.T) {
+	store := newMemoryStore()
+	tc := Wrap(pgocache.New(pgocache.DefaultExpiration, 0), WithWriteBehind(store))
+
+	store.failures = 1
+	tc.Set(context.Background(), "a", 1, pgocache.DefaultExpiration)
+	if err := tc.Close(context.Background()); err == nil {
+		t.Error("expected the failed write to be reported on close")
+	}
+}
+
+func TestWriteBehindSpan(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	store := newMemoryStore()
+	tc := Wrap(pgocache.New(pgocache.DefaultExpiration, 0),
+		WithAllTraceOptions(),
+		WithOpenTelemetry(tp, nil),
+		WithWriteBehind(store),
+	)
+
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "parent")
+	tc.Set(ctx, "a", 1, pgocache.DefaultExpiration)
+	parent.End()
+	tc.Close(context.Background())
+
+	spans := map[string]sdktrace.ReadOnlySpan{}
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+	set, put := spans["go.cache.set"], spans["go.cache.store.put"]
+	if set == nil || put == nil {
+		t.Fatal("expected set and store put spans, got:", spans)
+	}
+	if put.Parent().SpanID() != set.SpanContext().SpanID() {
+		t.Error("expected the write behind span to be a child of the originating call")
+	}
+}
@@ -0,0 +1,124 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// tagIndex maps tags to the keys stored with them and back
+type tagIndex struct {
+	mu    sync.Mutex
+	byTag map[string]map[string]struct{}
+	byKey map[string][]string
+}
+
+func newTagIndex() *tagIndex {
+	return &tagIndex{
+		byTag: make(map[string]map[string]struct{}),
+		byKey: make(map[string][]string),
+	}
+}
+
+// set replaces the tags of k
+func (t *tagIndex) set(k string, tags []string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.untag(k)
+	if len(tags) == 0 {
+		return
+	}
+	for _, tag := range tags {
+		keys, ok := t.byTag[tag]
+		if !ok {
+			keys = make(map[string]struct{})
+			t.byTag[tag] = keys
+		}
+		keys[k] = struct{}{}
+	}
+	t.byKey[k] = append([]string(nil), tags...)
+}
+
+// keys returns the keys tagged with tag
+func (t *tagIndex) keys(tag string) []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	keys := make([]string, 0, len(t.byTag[tag]))
+	for k := range t.byTag[tag] {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+func (t *tagIndex) remove(k string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.untag(k)
+}
+
+func (t *tagIndex) reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.byTag = make(map[string]map[string]struct{})
+	t.byKey = make(map[string][]string)
+}
+
+// untag removes k from the index, the caller holds mu
+func (t *tagIndex) untag(k string) {
+	for _, tag := range t.byKey[k] {
+		delete(t.byTag[tag], k)
+		if len(t.byTag[tag]) == 0 {
+			delete(t.byTag, tag)
+		}
+	}
+	delete(t.byKey, k)
+}
+
+// tagNames scopes tags to the namespace of the Wrapper
+func (w *Wrapper) tagNames(tags []string) []string {
+	if w.prefix == "" {
+		return tags
+	}
+	names := make([]string, len(tags))
+	for i, tag := range tags {
+		names[i] = w.prefix + tag
+	}
+	return names
+}
+
+// SetWithTags stores x under k as Set does and associates it with tags, replacing any tags k was stored with.
+// Tags are kept when k is overwritten by other writes and dropped once the item leaves the cache.
+func (w *Wrapper) SetWithTags(ctx context.Context, k string, x interface{}, d time.Duration, tags ...string) {
+	var err error
+	ctx, end := w.startKeyOp(ctx, "go.cache.setwithtags", w.options.SetWithTags, k, x)
+	defer func() {
+		end(w.storedResult(writeResult(err), nil, d))
+	}()
+
+	k, d = w.key(k), w.jitter(d)
+	names := w.tagNames(tags)
+	err = w.write(ctx, k, x, d, persistAndCache, func() {
+		w.tags.set(k, names)
+	})
+}
+
+// InvalidateTag deletes every item stored with tag, reporting them as invalidated, and returns the number of items
+// deleted. Keys whose item already left the cache are examined but not counted.
+func (w *Wrapper) InvalidateTag(ctx context.Context, tag string) (n int) {
+	keys := w.tags.keys(w.prefix + tag)
+
+	ctx, end := w.startBatchOp(ctx, "go.cache.invalidatetag", w.options.InvalidateTag, len(keys))
+	defer func() {
+		end(CalledResult().WithScan(len(keys), n))
+	}()
+
+	for _, k := range keys {
+		if removed, _ := w.remove(ctx, k, EvictionReasonInvalidated); removed {
+			n++
+		}
+	}
+
+	return
+}
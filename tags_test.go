@@ -0,0 +1,107 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pgocache "github.com/patrickmn/go-cache"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestInvalidateTag(t *testing.T) {
+	tc := Wrap(pgocache.New(pgocache.DefaultExpiration, 0), WithAllTraceOptions())
+	r := &evictionRecorder{reasons: make(map[string]EvictionReason)}
+	tc.AddEvictionListener(context.Background(), r.record)
+
+	tc.SetWithTags(context.Background(), "user:1", 1, pgocache.DefaultExpiration, "tenant:42")
+	tc.SetWithTags(context.Background(), "user:2", 2, pgocache.DefaultExpiration, "tenant:42", "admins")
+	tc.SetWithTags(context.Background(), "user:3", 3, pgocache.DefaultExpiration, "tenant:7")
+
+	if n := tc.InvalidateTag(context.Background(), "tenant:42"); n != 2 {
+		t.Error("expected 2 keys to be invalidated, got:", n)
+	}
+	if _, found := tc.Get(context.Background(), "user:1"); found {
+		t.Error("expected user:1 to be invalidated")
+	}
+	if _, found := tc.Get(context.Background(), "user:3"); !found {
+		t.Error("expected user:3 to be kept")
+	}
+	if reason := r.reason("user:2"); reason != EvictionReasonInvalidated {
+		t.Error("expected user:2 to be evicted as invalidated, got:", reason)
+	}
+	if keys := tc.tags.keys("admins"); len(keys) != 0 {
+		t.Error("expected invalidated keys to be removed from every tag, got:", keys)
+	}
+}
+
+func TestSetWithTagsReplacesTags(t *testing.T) {
+	tc := Wrap(pgocache.New(pgocache.DefaultExpiration, 0))
+
+	tc.SetWithTags(context.Background(), "a", 1, pgocache.DefaultExpiration, "x")
+	tc.SetWithTags(context.Background(), "a", 2, pgocache.DefaultExpiration, "y")
+
+	if n := tc.InvalidateTag(context.Background(), "x"); n != 0 {
+		t.Error("expected the previous tags to be replaced, got:", n)
+	}
+	if n := tc.InvalidateTag(context.Background(), "y"); n != 1 {
+		t.Error("expected a to be invalidated, got:", n)
+	}
+}
+
+func TestTagsExpire(t *testing.T) {
+	tc := Wrap(pgocache.New(pgocache.DefaultExpiration, 0))
+
+	tc.SetWithTags(context.Background(), "a", 1, time.Millisecond, "x")
+	tc.SetWithTags(context.Background(), "b", 1, pgocache.DefaultExpiration, "x")
+	tc.Delete(context.Background(), "b")
+	<-time.After(5 * time.Millisecond)
+	tc.DeleteExpired(context.Background())
+
+	if keys := tc.tags.keys("x"); len(keys) != 0 {
+		t.Error("expected evicted keys to be removed from the tag index, got:", keys)
+	}
+}
+
+func TestInvalidateTagCountsRemoved(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	tc := Wrap(pgocache.New(pgocache.DefaultExpiration, 0),
+		WithAllTraceOptions(),
+		WithAllowRoot(true),
+		WithOpenTelemetry(tp, nil),
+		WithTTLJitterDuration(10*time.Second),
+	)
+	tc.SetWithTags(context.Background(), "a", 1, time.Minute, "x")
+	// a key left in the index without an item is examined but not removed
+	tc.tags.set("b", []string{"x"})
+
+	if n := tc.InvalidateTag(context.Background(), "x"); n != 1 {
+		t.Error("expected only the cached item to be counted, got:", n)
+	}
+
+	ended := recorder.Ended()
+	if len(ended) != 2 {
+		t.Fatal("expected 2 spans, got:", len(ended))
+	}
+	for _, span := range ended {
+		attrs := map[attribute.Key]attribute.Value{}
+		for _, attr := range span.Attributes() {
+			attrs[attr.Key] = attr.Value
+		}
+		switch span.Name() {
+		case "go.cache.setwithtags":
+			exp, err := time.Parse(time.RFC3339Nano, attrs["cache.expiration"].AsString())
+			if until := time.Until(exp); err != nil || until < 49*time.Second || until > time.Minute {
+				t.Error("expected a jittered cache.expiration attribute, got:", attrs["cache.expiration"].AsString())
+			}
+		case "go.cache.invalidatetag":
+			if examined, removed := attrs["cache.keys_examined"].AsInt64(), attrs["cache.keys_removed"].AsInt64(); examined != 2 || removed != 1 {
+				t.Errorf("expected 2 keys examined and 1 removed, got %d and %d", examined, removed)
+			}
+		}
+	}
+}
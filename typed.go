@@ -0,0 +1,144 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// ErrTypeMismatch is matched by the errors Typed returns when an item is not of the expected type
+var ErrTypeMismatch = errors.New("cache: type mismatch")
+
+// TypeMismatchError reports an item stored under Key that is not of the type Want
+type TypeMismatchError struct {
+	Key  string
+	Want string
+	Got  string
+}
+
+func (e *TypeMismatchError) Error() string {
+	return fmt.Sprintf("cache: item %s is of type %s, not %s", e.Key, e.Got, e.Want)
+}
+
+// Is reports whether target is ErrTypeMismatch
+func (e *TypeMismatchError) Is(target error) bool {
+	return target == ErrTypeMismatch
+}
+
+// TypedLoaderFunc computes the value for a key missing from a Typed cache along with the duration it should be cached for
+type TypedLoaderFunc[V any] func(ctx context.Context) (V, time.Duration, error)
+
+// Typed is a view over a Wrapper holding values of type V. Items of another type are reported with a
+// TypeMismatchError and the StatusTypeMismatch status rather than a failed type assertion.
+type Typed[V any] struct {
+	w *Wrapper
+}
+
+// NewTyped returns a view over w holding values of type V
+func NewTyped[V any](w *Wrapper) *Typed[V] {
+	return &Typed[V]{w: w}
+}
+
+// Wrapper returns the Wrapper t is a view over
+func (t *Typed[V]) Wrapper() *Wrapper {
+	return t.w
+}
+
+// Get returns the item stored under k, found is false if it is missing and err is a TypeMismatchError if it is not a V
+func (t *Typed[V]) Get(ctx context.Context, k string) (v V, found bool, err error) {
+	var x interface{}
+	ctx, end := t.w.startKeyOp(ctx, "go.cache.get", t.w.options.Get, k, nil)
+	defer func() {
+		end(typedResult(lookupResult(x, found), err))
+	}()
+
+	key := t.w.key(k)
+	if x, _, found = t.w.get(ctx, "go.cache.get", key); !found && !IsAbsent(x) && t.w.readThrough(ctx, key) {
+		x, _, found = t.w.get(ctx, "go.cache.get", key)
+	}
+	if found {
+		v, err = assertType[V](k, x)
+	}
+
+	return
+}
+
+// Set stores x under k, replacing any existing item
+func (t *Typed[V]) Set(ctx context.Context, k string, x V, d time.Duration) {
+	t.w.Set(ctx, k, x, d)
+}
+
+// Add stores x under k if no item exists for it
+func (t *Typed[V]) Add(ctx context.Context, k string, x V, d time.Duration) error {
+	return t.w.Add(ctx, k, x, d)
+}
+
+// Replace stores x under k if an item already exists for it
+func (t *Typed[V]) Replace(ctx context.Context, k string, x V, d time.Duration) error {
+	return t.w.Replace(ctx, k, x, d)
+}
+
+// GetOrLoad returns the item stored under k, loading and storing it on a miss as Wrapper.GetOrLoad does. ErrAbsent is
+// returned for keys known to be absent.
+func (t *Typed[V]) GetOrLoad(ctx context.Context, k string, loader TypedLoaderFunc[V]) (v V, err error) {
+	var (
+		x     interface{}
+		found bool
+	)
+	ctx, end := t.w.startKeyOp(ctx, "go.cache.getorload", t.w.options.GetOrLoad, k, nil)
+	defer func() {
+		end(typedResult(loadResult(x, found, err), err))
+	}()
+
+	x, found, err = t.w.getOrLoad(ctx, "go.cache.getorload", t.w.key(k), func(ctx context.Context) (interface{}, time.Duration, error) {
+		return loader(ctx)
+	})
+	if err != nil {
+		return
+	}
+	v, err = assertType[V](k, x)
+
+	return
+}
+
+// Items returns the unexpired items holding a V, err is a TypeMismatchError for the first item found holding another type
+func (t *Typed[V]) Items(ctx context.Context) (items map[string]V, err error) {
+	ctx, end := t.w.startOp(ctx, "go.cache.items", t.w.options.Items)
+	defer func() {
+		end(typedResult(CalledResult(), err))
+	}()
+
+	all := t.w.items()
+	items = make(map[string]V, len(all))
+	for k, item := range all {
+		v, mismatch := assertType[V](k, item.Object)
+		if mismatch != nil {
+			if err == nil {
+				err = mismatch
+			}
+			continue
+		}
+		items[k] = v
+	}
+
+	return
+}
+
+// assertType returns x as a V, or a TypeMismatchError if it is not one
+func assertType[V any](k string, x interface{}) (V, error) {
+	v, ok := x.(V)
+	if !ok {
+		return v, &TypeMismatchError{Key: k, Want: reflect.TypeOf((*V)(nil)).Elem().String(), Got: fmt.Sprintf("%T", x)}
+	}
+	return v, nil
+}
+
+// typedResult replaces res with a StatusTypeMismatch Result when err is a type mismatch
+func typedResult(res Result, err error) Result {
+	if errors.Is(err, ErrTypeMismatch) {
+		return Result{Status: StatusTypeMismatch, Err: err, Value: res.Value}
+	}
+	return res
+}
@@ -0,0 +1,97 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	pgocache "github.com/patrickmn/go-cache"
+)
+
+type user struct {
+	Name string
+}
+
+func TestTyped(t *testing.T) {
+	tc := Wrap(pgocache.New(pgocache.DefaultExpiration, 0), WithAllTraceOptions())
+	users := NewTyped[user](tc)
+
+	users.Set(context.Background(), "a", user{Name: "alice"}, pgocache.DefaultExpiration)
+	if u, found, err := users.Get(context.Background(), "a"); err != nil || !found || u.Name != "alice" {
+		t.Error("unexpected result:", u, found, err)
+	}
+	if _, found, err := users.Get(context.Background(), "b"); err != nil || found {
+		t.Error("expected a miss, got:", found, err)
+	}
+	if err := users.Add(context.Background(), "a", user{}, pgocache.DefaultExpiration); err == nil {
+		t.Error("expected an error adding an existing key")
+	}
+	if err := users.Replace(context.Background(), "a", user{Name: "bob"}, pgocache.DefaultExpiration); err != nil {
+		t.Error("Error replacing a:", err)
+	}
+
+	u, err := users.GetOrLoad(context.Background(), "c", func(ctx context.Context) (user, time.Duration, error) {
+		return user{Name: "carol"}, pgocache.DefaultExpiration, nil
+	})
+	if err != nil || u.Name != "carol" {
+		t.Error("unexpected result:", u, err)
+	}
+
+	items, err := users.Items(context.Background())
+	if err != nil || len(items) != 2 || items["a"].Name != "bob" {
+		t.Error("unexpected items:", items, err)
+	}
+}
+
+func TestTypedReadThrough(t *testing.T) {
+	store := newMemoryStore()
+	store.items["a"] = user{Name: "alice"}
+	users := NewTyped[user](Wrap(pgocache.New(pgocache.DefaultExpiration, 0), WithWriteThrough(store)))
+
+	if u, found, err := users.Get(context.Background(), "a"); err != nil || !found || u.Name != "alice" {
+		t.Error("expected a miss to be read through, got:", u, found, err)
+	}
+}
+
+func TestTypedMismatch(t *testing.T) {
+	r := &recordingInstrumenter{}
+	tc := Wrap(pgocache.New(pgocache.DefaultExpiration, 0), WithInstrumenter(r))
+	users := NewTyped[user](tc)
+
+	tc.Set(context.Background(), "a", "not a user", pgocache.DefaultExpiration)
+	tc.Set(context.Background(), "b", user{Name: "bob"}, pgocache.DefaultExpiration)
+
+	_, found, err := users.Get(context.Background(), "a")
+	var mismatch *TypeMismatchError
+	if !found || !errors.Is(err, ErrTypeMismatch) || !errors.As(err, &mismatch) {
+		t.Fatal("expected a type mismatch, got:", found, err)
+	}
+	if mismatch.Key != "a" || mismatch.Want != "cache.user" || mismatch.Got != "string" {
+		t.Error("unexpected mismatch:", mismatch)
+	}
+
+	if _, err := users.GetOrLoad(context.Background(), "a", nil); !errors.Is(err, ErrTypeMismatch) {
+		t.Error("expected a type mismatch, got:", err)
+	}
+
+	items, err := users.Items(context.Background())
+	if !errors.Is(err, ErrTypeMismatch) || len(items) != 1 || items["b"].Name != "bob" {
+		t.Error("expected mismatched items to be skipped, got:", items, err)
+	}
+
+	var statuses []string
+	for _, op := range r.results() {
+		statuses = append(statuses, op.res.Status)
+	}
+	want := []string{StatusCalled, StatusCalled, StatusTypeMismatch, StatusTypeMismatch, StatusTypeMismatch}
+	if len(statuses) != len(want) {
+		t.Fatal("expected", want, "got:", statuses)
+	}
+	for i := range want {
+		if statuses[i] != want[i] {
+			t.Error("expected", want, "got:", statuses)
+			break
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// UpdateFunc computes the new value of an item from its current value, found is false if the item is missing or
+// known to be absent.
+// The new value is stored with the returned duration, returning an error leaves the item unchanged.
+type UpdateFunc func(old interface{}, found bool) (new interface{}, d time.Duration, err error)
+
+// Update atomically replaces the item stored under k with the result of f. Update and CompareAndSwap are
+// linearizable with each other and with Set, Add and Replace on the same key. f is called with k locked,
+// it must not write to k through the Wrapper. The error of the Store, if any, is returned along with v once cached.
+func (w *Wrapper) Update(ctx context.Context, k string, f UpdateFunc) (v interface{}, err error) {
+	ctx, end := w.startKeyOp(ctx, "go.cache.update", w.options.Update, k, nil)
+	defer func() {
+		end(ErrorResult(err).WithValue(v))
+	}()
+
+	k = w.key(k)
+	old, found, v, cached, err := w.update(ctx, k, f)
+	if !cached {
+		return nil, err
+	}
+
+	w.stored(ctx, k, v, old, found)
+
+	return
+}
+
+// update stores the result of f under k with k locked, the lock is released even if f panics. cached is false if
+// f failed, err is then that of f rather than of the Store.
+func (w *Wrapper) update(ctx context.Context, k string, f UpdateFunc) (old interface{}, found bool, v interface{}, cached bool, err error) {
+	unlock := w.locks.lock(k)
+	defer unlock()
+
+	if old, found = w.Cache.Get(k); IsAbsent(old) {
+		old, found = nil, false
+	}
+	var d time.Duration
+	if v, d, err = f(old, found); err != nil {
+		return
+	}
+	w.Cache.Set(k, v, w.expiration(d))
+	w.written(k, nil)
+
+	return old, found, v, true, w.persist(ctx, k, v, d)
+}
+
+// CompareAndSwap stores new under k if the item stored under k is equal to old, keeping its expiration.
+// swapped is false if the item is missing, has expired, is known to be absent or holds another value, err is set if old is not comparable
+// or by the Store once swapped.
+func (w *Wrapper) CompareAndSwap(ctx context.Context, k string, old, new interface{}) (swapped bool, err error) {
+	ctx, end := w.startKeyOp(ctx, "go.cache.compareandswap", w.options.CompareAndSwap, k, new)
+	defer func() {
+		end(ErrorResult(err))
+	}()
+
+	k = w.key(k)
+	if old != nil && !reflect.TypeOf(old).Comparable() {
+		return false, fmt.Errorf("cache: CompareAndSwap of %s with uncomparable type %T", k, old)
+	}
+
+	var current interface{}
+	if current, swapped, err = w.compareAndSwap(ctx, k, old, new); swapped {
+		w.stored(ctx, k, new, current, true)
+	}
+
+	return
+}
+
+// compareAndSwap stores new under k with its remaining expiration if it holds old, with k locked
+func (w *Wrapper) compareAndSwap(ctx context.Context, k string, old, new interface{}) (current interface{}, swapped bool, err error) {
+	unlock := w.locks.lock(k)
+	defer unlock()
+
+	current, exp, found := w.Cache.GetWithExpiration(k)
+	if !found || IsAbsent(current) || !equal(current, old) {
+		return current, false, nil
+	}
+	d, ok := remaining(exp)
+	if !ok {
+		return current, false, nil
+	}
+	w.Cache.Set(k, new, d)
+	w.written(k, nil)
+
+	return current, true, w.persist(ctx, k, new, d)
+}
+
+// equal compares a and b without panicking on uncomparable dynamic types
+func equal(a, b interface{}) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if reflect.TypeOf(a) != reflect.TypeOf(b) || !reflect.TypeOf(a).Comparable() {
+		return false
+	}
+	return a == b
+}
+
+// keyLocks serializes writes to the same key, the lock for a key is dropped once it has no holders or waiters
+type keyLocks struct {
+	mu    sync.Mutex
+	locks map[string]*keyLock
+}
+
+type keyLock struct {
+	sync.Mutex
+	refs int
+}
+
+func newKeyLocks() *keyLocks {
+	return &keyLocks{
+		locks: make(map[string]*keyLock),
+	}
+}
+
+// lock locks k and returns the function unlocking it
+func (l *keyLocks) lock(k string) (unlock func()) {
+	l.mu.Lock()
+	kl, ok := l.locks[k]
+	if !ok {
+		kl = &keyLock{}
+		l.locks[k] = kl
+	}
+	kl.refs++
+	l.mu.Unlock()
+
+	kl.Lock()
+
+	return func() {
+		kl.Unlock()
+
+		l.mu.Lock()
+		if kl.refs--; kl.refs == 0 {
+			delete(l.locks, k)
+		}
+		l.mu.Unlock()
+	}
+}
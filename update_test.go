@@ -0,0 +1,144 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	pgocache "github.com/patrickmn/go-cache"
+)
+
+func TestUpdate(t *testing.T) {
+	tc := Wrap(pgocache.New(pgocache.DefaultExpiration, 0), WithAllTraceOptions())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := tc.Update(context.Background(), "counter", func(old interface{}, found bool) (interface{}, time.Duration, error) {
+				if !found {
+					return 1, pgocache.DefaultExpiration, nil
+				}
+				return old.(int) + 1, pgocache.DefaultExpiration, nil
+			})
+			if err != nil {
+				t.Error("Error updating counter:", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if v, _ := tc.Get(context.Background(), "counter"); v != 100 {
+		t.Error("expected 100 linearizable updates, got:", v)
+	}
+}
+
+func TestUpdateError(t *testing.T) {
+	tc := Wrap(pgocache.New(pgocache.DefaultExpiration, 0))
+	tc.Set(context.Background(), "a", 1, pgocache.DefaultExpiration)
+
+	errUpdate := errors.New("update failed")
+	if _, err := tc.Update(context.Background(), "a", func(old interface{}, found bool) (interface{}, time.Duration, error) {
+		return nil, 0, errUpdate
+	}); err != errUpdate {
+		t.Error("expected the update error, got:", err)
+	}
+	if v, _ := tc.Get(context.Background(), "a"); v != 1 {
+		t.Error("expected a failed update to leave the item unchanged, got:", v)
+	}
+}
+
+func TestUpdatePanic(t *testing.T) {
+	tc := Wrap(pgocache.New(pgocache.DefaultExpiration, 0))
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("expected the panic of f to propagate")
+			}
+		}()
+		tc.Update(context.Background(), "a", func(old interface{}, found bool) (interface{}, time.Duration, error) {
+			panic("update failed")
+		})
+	}()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		tc.Set(context.Background(), "a", 1, pgocache.DefaultExpiration)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("expected a panic in f to unlock the key")
+	}
+}
+
+func TestRemaining(t *testing.T) {
+	if d, ok := remaining(time.Time{}); !ok || d != pgocache.NoExpiration {
+		t.Error("expected items without an expiration to be kept forever, got:", d, ok)
+	}
+	if d, ok := remaining(time.Now().Add(time.Minute)); !ok || d <= 0 || d > time.Minute {
+		t.Error("expected the remaining duration, got:", d, ok)
+	}
+	if _, ok := remaining(time.Now().Add(-time.Nanosecond)); ok {
+		t.Error("expected an expired item not to be stored again")
+	}
+}
+
+func TestCompareAndSwap(t *testing.T) {
+	tc := Wrap(pgocache.New(pgocache.DefaultExpiration, 0), WithAllTraceOptions())
+	tc.Set(context.Background(), "a", "x", time.Hour)
+
+	if swapped, err := tc.CompareAndSwap(context.Background(), "a", "y", "z"); err != nil || swapped {
+		t.Error("expected no swap of a different value, got:", swapped, err)
+	}
+	if swapped, err := tc.CompareAndSwap(context.Background(), "b", "x", "z"); err != nil || swapped {
+		t.Error("expected no swap of a missing item, got:", swapped, err)
+	}
+	if swapped, err := tc.CompareAndSwap(context.Background(), "a", "x", "z"); err != nil || !swapped {
+		t.Error("expected a swap, got:", swapped, err)
+	}
+
+	v, exp, _ := tc.GetWithExpiration(context.Background(), "a")
+	if v != "z" {
+		t.Error("expected the swapped value, got:", v)
+	}
+	if until := time.Until(exp); until <= 59*time.Minute || until > time.Hour {
+		t.Error("expected the expiration to be kept, got:", until)
+	}
+
+	if _, err := tc.CompareAndSwap(context.Background(), "a", []string{"z"}, "y"); err == nil {
+		t.Error("expected an error comparing an uncomparable value")
+	}
+}
+
+func TestCompareAndSwapConcurrent(t *testing.T) {
+	tc := Wrap(pgocache.New(pgocache.DefaultExpiration, 0))
+	tc.Set(context.Background(), "a", 0, pgocache.DefaultExpiration)
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		swapped int
+	)
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if ok, _ := tc.CompareAndSwap(context.Background(), "a", 0, i+1); ok {
+				mu.Lock()
+				swapped++
+				mu.Unlock()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if swapped != 1 {
+		t.Error("expected exactly one swap, got:", swapped)
+	}
+}
@@ -0,0 +1,135 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrVersionMismatch is matched by the errors SetIfVersion returns when the version of an item has moved
+var ErrVersionMismatch = errors.New("cache: version mismatch")
+
+// VersionMismatchError reports that the item stored under Key is at version Got rather than Want
+type VersionMismatchError struct {
+	Key  string
+	Want uint64
+	Got  uint64
+}
+
+func (e *VersionMismatchError) Error() string {
+	return fmt.Sprintf("cache: item %s is at version %d, not %d", e.Key, e.Got, e.Want)
+}
+
+// Is reports whether target is ErrVersionMismatch
+func (e *VersionMismatchError) Is(target error) bool {
+	return target == ErrVersionMismatch
+}
+
+// versions tracks the version of each key. Versions are drawn from a single counter so that a key
+// deleted and stored again never reuses a version it was previously at.
+type versions struct {
+	mu   sync.Mutex
+	seq  uint64
+	keys map[string]uint64
+}
+
+func newVersions() *versions {
+	return &versions{
+		keys: make(map[string]uint64),
+	}
+}
+
+// bump moves k to a new version
+func (v *versions) bump(k string) uint64 {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.seq++
+	v.keys[k] = v.seq
+	return v.seq
+}
+
+// get returns the version of k, assigning one to items stored without going through a Wrapper write
+func (v *versions) get(k string) uint64 {
+	v.mu.Lock()
+	version, ok := v.keys[k]
+	v.mu.Unlock()
+	if !ok {
+		return v.bump(k)
+	}
+	return version
+}
+
+func (v *versions) remove(k string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	delete(v.keys, k)
+}
+
+func (v *versions) reset() {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.keys = make(map[string]uint64)
+}
+
+// GetVersioned returns the item stored under k along with its version. Every write to k through the
+// Wrapper moves it to a higher version, missing items are at version 0.
+func (w *Wrapper) GetVersioned(ctx context.Context, k string) (v interface{}, version uint64, found bool) {
+	ctx, end := w.startKeyOp(ctx, "go.cache.getversioned", w.options.GetVersioned, k, nil)
+	defer func() {
+		end(FoundResult(found).WithValue(v))
+	}()
+
+	k = w.key(k)
+	var exp time.Time
+	unlock := w.locks.lock(k)
+	if v, exp, found = w.Cache.GetWithExpiration(k); found && !IsAbsent(v) {
+		version = w.versions.get(k)
+	}
+	unlock()
+
+	// the read may slide or refresh the item, which locks k again
+	if found {
+		_, found = w.served(ctx, "go.cache.getversioned", k, v, exp)
+	}
+
+	return
+}
+
+// SetIfVersion stores x under k if the item is still at version, as returned by GetVersioned, and returns its
+// new version. Version 0 stores x only if k is missing or known to be absent. A VersionMismatchError is returned if the version has moved,
+// the error of the Store is returned along with the new version once x is cached.
+func (w *Wrapper) SetIfVersion(ctx context.Context, k string, x interface{}, d time.Duration, version uint64) (next uint64, err error) {
+	ctx, end := w.startKeyOp(ctx, "go.cache.setifversion", w.options.SetIfVersion, k, x)
+	defer func() {
+		end(ErrorResult(err))
+	}()
+
+	k = w.key(k)
+	unlock := w.locks.lock(k)
+	old, found := w.Cache.Get(k)
+	var current uint64
+	if found && !IsAbsent(old) {
+		current = w.versions.get(k)
+	}
+	if current != version {
+		unlock()
+		return current, &VersionMismatchError{Key: k, Want: version, Got: current}
+	}
+	w.Cache.Set(k, x, w.expiration(d))
+	next = w.versions.bump(k)
+	err = w.persist(ctx, k, x, d)
+	unlock()
+
+	w.stored(ctx, k, x, old, found)
+
+	return
+}
+
+// written moves k to a new version after a successful write, the caller holds the lock on k
+func (w *Wrapper) written(k string, err error) {
+	if err == nil {
+		w.versions.bump(k)
+	}
+}
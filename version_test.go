@@ -0,0 +1,99 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	pgocache "github.com/patrickmn/go-cache"
+)
+
+func TestVersions(t *testing.T) {
+	tc := Wrap(pgocache.New(pgocache.DefaultExpiration, 0), WithAllTraceOptions())
+
+	if _, version, found := tc.GetVersioned(context.Background(), "a"); found || version != 0 {
+		t.Error("expected a missing item at version 0, got:", version, found)
+	}
+
+	tc.Set(context.Background(), "a", 1, pgocache.DefaultExpiration)
+	_, v1, _ := tc.GetVersioned(context.Background(), "a")
+
+	if err := tc.Increment(context.Background(), "a", 1); err != nil {
+		t.Fatal("Error incrementing a:", err)
+	}
+	x, v2, _ := tc.GetVersioned(context.Background(), "a")
+	if x != 2 || v2 <= v1 {
+		t.Errorf("expected the increment to move the version past %d, got %v at %d", v1, x, v2)
+	}
+
+	tc.Delete(context.Background(), "a")
+	tc.Set(context.Background(), "a", 1, pgocache.DefaultExpiration)
+	if _, v3, _ := tc.GetVersioned(context.Background(), "a"); v3 <= v2 {
+		t.Errorf("expected a version past %d after storing a again, got %d", v2, v3)
+	}
+}
+
+func TestSetIfVersion(t *testing.T) {
+	tc := Wrap(pgocache.New(pgocache.DefaultExpiration, 0), WithAllTraceOptions())
+
+	v1, err := tc.SetIfVersion(context.Background(), "a", 1, pgocache.DefaultExpiration, 0)
+	if err != nil {
+		t.Fatal("Error storing a missing item at version 0:", err)
+	}
+	if _, err := tc.SetIfVersion(context.Background(), "a", 1, pgocache.DefaultExpiration, 0); !errors.Is(err, ErrVersionMismatch) {
+		t.Error("expected a version mismatch storing an existing item at version 0, got:", err)
+	}
+
+	v2, err := tc.SetIfVersion(context.Background(), "a", 2, pgocache.DefaultExpiration, v1)
+	if err != nil || v2 <= v1 {
+		t.Fatal("unexpected result:", v2, err)
+	}
+
+	_, err = tc.SetIfVersion(context.Background(), "a", 3, time.Minute, v1)
+	var mismatch *VersionMismatchError
+	if !errors.As(err, &mismatch) || mismatch.Want != v1 || mismatch.Got != v2 {
+		t.Error("expected a version mismatch, got:", err)
+	}
+	if x, _ := tc.Get(context.Background(), "a"); x != 2 {
+		t.Error("expected a lost update to be rejected, got:", x)
+	}
+}
+
+func TestVersionStatus(t *testing.T) {
+	r := &recordingInstrumenter{}
+	tc := Wrap(pgocache.New(pgocache.DefaultExpiration, 0), WithInstrumenter(r))
+
+	tc.Set(context.Background(), "a", 1, pgocache.DefaultExpiration)
+	tc.SetIfVersion(context.Background(), "a", 2, pgocache.DefaultExpiration, 0)
+
+	ops := r.results()
+	if last := ops[len(ops)-1]; last.op.Method != "go.cache.setifversion" || last.res.Status != StatusError {
+		t.Error("expected the mismatch to be recorded as an error, got:", last)
+	}
+}
+
+func TestGetVersionedSliding(t *testing.T) {
+	tc := Wrap(pgocache.New(pgocache.DefaultExpiration, 0), WithSlidingTTL(time.Minute))
+	tc.SetSliding(context.Background(), "a", 1, time.Hour)
+	tc.Set(context.Background(), "b", 1, time.Second)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for _, k := range []string{"a", "b"} {
+			if _, version, found := tc.GetVersioned(context.Background(), k); !found || version == 0 {
+				t.Errorf("%s: expected a versioned item, got: %d %v", k, version, found)
+			}
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("expected GetVersioned to slide the expiration without deadlocking")
+	}
+
+	if _, exp, _ := tc.Cache.GetWithExpiration("b"); time.Until(exp) < 59*time.Second {
+		t.Error("expected GetVersioned to slide the expiration, got:", exp)
+	}
+}
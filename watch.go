@@ -0,0 +1,215 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// DefaultWatchBuffer is the number of events buffered for each Watch subscriber when WatchBuffer is not set
+const DefaultWatchBuffer = 64
+
+// EventType describes the change to a key reported by an Event
+type EventType string
+
+// The following event types are emitted to Watch subscribers
+const (
+	// EventSet is emitted when an item is stored under a key that did not hold one
+	EventSet EventType = "SET"
+
+	// EventReplace is emitted when the item stored under a key is overwritten or incremented
+	EventReplace EventType = "REPLACE"
+
+	// EventDelete is emitted when an item is deleted, invalidated or evicted to stay within capacity
+	EventDelete EventType = "DELETE"
+
+	// EventExpire is emitted when go-cache deletes an expired item
+	EventExpire EventType = "EXPIRE"
+
+	// EventFlush is emitted for each item removed by Flush
+	EventFlush EventType = "FLUSH"
+)
+
+// Event describes a change to a watched key
+type Event struct {
+	Type EventType
+	Key  string
+
+	// Value is the new value for EventSet and EventReplace, and the removed value otherwise
+	Value interface{}
+
+	// Reason is the eviction reason of removals
+	Reason EvictionReason
+}
+
+// subscription delivers the events of the keys matching key, or starting with it if prefix is set
+type subscription struct {
+	key    string
+	prefix bool
+
+	// strip is the namespace prefix removed from the keys of delivered events
+	strip string
+	ch    chan Event
+
+	// by is the Wrapper, or namespace of it, that subscribed, dropped events are recorded with its Instrumenter
+	by *Wrapper
+}
+
+func (s *subscription) matches(k string) bool {
+	if s.prefix {
+		return strings.HasPrefix(k, s.key)
+	}
+	return k == s.key
+}
+
+// watchers holds the Watch subscriptions of a Wrapper and its namespaces
+type watchers struct {
+	mu   sync.RWMutex
+	subs map[*subscription]struct{}
+}
+
+func newWatchers() *watchers {
+	return &watchers{
+		subs: make(map[*subscription]struct{}),
+	}
+}
+
+func (ws *watchers) add(s *subscription) {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	ws.subs[s] = struct{}{}
+}
+
+// remove unsubscribes s and closes its channel, it is safe to call more than once
+func (ws *watchers) remove(s *subscription) {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	if _, ok := ws.subs[s]; ok {
+		delete(ws.subs, s)
+		close(s.ch)
+	}
+}
+
+// notify delivers e to the matching subscribers without blocking, counting the events dropped for full buffers
+func (ws *watchers) notify(ctx context.Context, e Event) {
+	ws.mu.RLock()
+	defer ws.mu.RUnlock()
+
+	for s := range ws.subs {
+		if !s.matches(e.Key) {
+			continue
+		}
+		se := e
+		se.Key = e.Key[len(s.strip):]
+		select {
+		case s.ch <- se:
+		default:
+			s.by.signal(ctx, Signal{Kind: SignalWatchDrop})
+		}
+	}
+}
+
+func (ws *watchers) empty() bool {
+	ws.mu.RLock()
+	defer ws.mu.RUnlock()
+	return len(ws.subs) == 0
+}
+
+// written notifies subscribers that x was stored under k
+func (ws *watchers) written(ctx context.Context, k string, x interface{}, replaced bool) {
+	if ws.empty() {
+		return
+	}
+	e := Event{Type: EventSet, Key: k, Value: x}
+	if replaced {
+		e.Type = EventReplace
+	}
+	ws.notify(ctx, e)
+}
+
+// evicted notifies subscribers that the item v stored under k left the cache for reason
+func (ws *watchers) evicted(ctx context.Context, k string, v interface{}, reason EvictionReason) {
+	if ws.empty() {
+		return
+	}
+	e := Event{Type: EventDelete, Key: k, Value: v, Reason: reason}
+	switch reason {
+	case EvictionReasonReplaced:
+		// reported by written as EventReplace
+		return
+	case EvictionReasonExpired:
+		e.Type = EventExpire
+	case EvictionReasonFlushed:
+		e.Type = EventFlush
+	}
+	ws.notify(ctx, e)
+}
+
+// incremented moves k to a new version, persists the new value and notifies subscribers after a successful
+// increment or decrement, returning err or else the error of the Store. A key known to be absent fails the increment
+// as a missing one does. The caller holds the lock on k.
+func (w *Wrapper) incremented(ctx context.Context, k string, err error) error {
+	if err != nil {
+		if v, found := w.Cache.Get(k); found && IsAbsent(v) {
+			// as returned by go-cache for missing items
+			err = fmt.Errorf("Item %s not found", k)
+		}
+	}
+	w.written(k, err)
+	if err != nil || (w.options.Store == nil && w.watchers.empty()) {
+		return err
+	}
+	v, exp, found := w.Cache.GetWithExpiration(k)
+	if !found {
+		return nil
+	}
+	if d, ok := remaining(exp); ok {
+		err = w.persist(ctx, k, v, d)
+	}
+	if !w.watchers.empty() {
+		w.watchers.notify(ctx, Event{Type: EventReplace, Key: k, Value: v})
+	}
+	return err
+}
+
+// Watch subscribes to the changes of keyOrPrefix, a key ending with * watches every key starting with the rest
+// of it. Events are buffered up to WatchBuffer, events for a subscriber whose buffer is full are dropped and
+// counted by MeasureWatchDrops. The channel is closed once cancel is called or ctx is done.
+func (w *Wrapper) Watch(ctx context.Context, keyOrPrefix string) (events <-chan Event, cancel func()) {
+	_, end := w.startKeyOp(ctx, "go.cache.watch", w.options.Watch, keyOrPrefix, nil)
+	defer func() {
+		end(CalledResult())
+	}()
+
+	size := w.options.WatchBuffer
+	if size <= 0 {
+		size = DefaultWatchBuffer
+	}
+	s := &subscription{
+		key:    w.key(strings.TrimSuffix(keyOrPrefix, "*")),
+		prefix: strings.HasSuffix(keyOrPrefix, "*"),
+		strip:  w.prefix,
+		by:     w,
+		ch:     make(chan Event, size),
+	}
+	w.watchers.add(s)
+
+	done := make(chan struct{})
+	var once sync.Once
+	cancel = func() {
+		once.Do(func() {
+			close(done)
+			w.watchers.remove(s)
+		})
+	}
+	go func() {
+		select {
+		case <-ctx.Done():
+			cancel()
+		case <-done:
+		}
+	}()
+
+	return s.ch, cancel
+}
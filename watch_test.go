@@ -0,0 +1,124 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pgocache "github.com/patrickmn/go-cache"
+	"go.opencensus.io/stats/view"
+)
+
+func receive(t *testing.T, events <-chan Event) Event {
+	t.Helper()
+	select {
+	case e := <-events:
+		return e
+	case <-time.After(time.Second):
+		t.Fatal("no event received")
+		return Event{}
+	}
+}
+
+func TestWatch(t *testing.T) {
+	tc := Wrap(pgocache.New(pgocache.DefaultExpiration, 0), WithAllTraceOptions())
+
+	events, cancel := tc.Watch(context.Background(), "a")
+	defer cancel()
+
+	tc.Set(context.Background(), "a", 1, pgocache.DefaultExpiration)
+	tc.Set(context.Background(), "b", 1, pgocache.DefaultExpiration)
+	tc.Set(context.Background(), "a", 2, pgocache.DefaultExpiration)
+	tc.Increment(context.Background(), "a", 1)
+	tc.Delete(context.Background(), "a")
+	tc.Set(context.Background(), "a", 1, time.Millisecond)
+	<-time.After(5 * time.Millisecond)
+	tc.DeleteExpired(context.Background())
+	tc.Set(context.Background(), "a", 1, pgocache.DefaultExpiration)
+	tc.Flush(context.Background())
+
+	for _, want := range []Event{
+		{Type: EventSet, Key: "a", Value: 1},
+		{Type: EventReplace, Key: "a", Value: 2},
+		{Type: EventReplace, Key: "a", Value: 3},
+		{Type: EventDelete, Key: "a", Value: 3, Reason: EvictionReasonDeleted},
+		{Type: EventSet, Key: "a", Value: 1},
+		{Type: EventExpire, Key: "a", Value: 1, Reason: EvictionReasonExpired},
+		{Type: EventSet, Key: "a", Value: 1},
+		{Type: EventFlush, Key: "a", Value: 1, Reason: EvictionReasonFlushed},
+	} {
+		if got := receive(t, events); got != want {
+			t.Errorf("expected %+v, got %+v", want, got)
+		}
+	}
+}
+
+func TestWatchPrefixNamespace(t *testing.T) {
+	tc := Wrap(pgocache.New(pgocache.DefaultExpiration, 0))
+	users := tc.Namespace("users")
+
+	events, cancel := users.Watch(context.Background(), "42:*")
+	defer cancel()
+
+	users.Set(context.Background(), "42:profile", 1, pgocache.DefaultExpiration)
+	tc.Set(context.Background(), "42:profile", 1, pgocache.DefaultExpiration)
+	users.Set(context.Background(), "42:settings", 1, pgocache.DefaultExpiration)
+
+	if e := receive(t, events); e.Key != "42:profile" {
+		t.Error("expected the namespace key unprefixed, got:", e.Key)
+	}
+	if e := receive(t, events); e.Key != "42:settings" {
+		t.Error("expected keys outside the namespace to be ignored, got:", e.Key)
+	}
+}
+
+func TestWatchCancel(t *testing.T) {
+	tc := Wrap(pgocache.New(pgocache.DefaultExpiration, 0))
+
+	ctx, cancelCtx := context.WithCancel(context.Background())
+	events, cancel := tc.Watch(ctx, "*")
+	cancelCtx()
+
+	select {
+	case _, ok := <-events:
+		if ok {
+			t.Error("expected no events after cancellation")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the channel to be closed once the context is done")
+	}
+	cancel()
+
+	tc.Set(context.Background(), "a", 1, pgocache.DefaultExpiration)
+}
+
+func TestWatchDrops(t *testing.T) {
+	if err := view.Register(GoCacheWatchDropsView); err != nil {
+		t.Fatal("Error registering view:", err)
+	}
+	defer view.Unregister(GoCacheWatchDropsView)
+
+	tc := Wrap(pgocache.New(pgocache.DefaultExpiration, 0), WithInstanceName("watch-drops"), WithWatchBuffer(1))
+	_, cancel := tc.Watch(context.Background(), "*")
+	defer cancel()
+
+	for i := 0; i < 3; i++ {
+		tc.Set(context.Background(), "a", i, pgocache.DefaultExpiration)
+	}
+
+	rows, err := view.RetrieveData(GoCacheWatchDropsView.Name)
+	if err != nil {
+		t.Fatal("Error retrieving view data:", err)
+	}
+	for _, row := range rows {
+		for _, tag := range row.Tags {
+			if tag.Key == GoCacheName && tag.Value == "watch-drops" {
+				if dropped := row.Data.(*view.SumData).Value; dropped != 2 {
+					t.Error("expected 2 dropped events, got:", dropped)
+				}
+				return
+			}
+		}
+	}
+	t.Error("no dropped events recorded:", rows)
+}
@@ -7,9 +7,15 @@ import (
 
 	pgocache "github.com/patrickmn/go-cache"
 	"go.opencensus.io/trace"
+	"golang.org/x/sync/singleflight"
 )
 
-// Wrap takes a cache instance and wraps it with OpenCensus instrumentation.
+// Wrap takes a cache instance and wraps it with OpenCensus instrumentation, or OpenTelemetry when configured with WithOpenTelemetry.
+// Use WithInstrumenter to replace the instrumentation altogether.
+// The Wrapper registers its own eviction callback with the cache, use Wrapper.OnEvicted to be notified of evictions.
+// This replaces any callback already registered with the cache, pass it with WithCacheOnEvicted to keep it called.
+// A cache must only be wrapped once, wrapping it again disconnects the first Wrapper from its evictions. Use
+// Wrapper.Namespace for further views over the same cache.
 func Wrap(c *pgocache.Cache, options ...TraceOption) *Wrapper {
 	o := TraceOptions{}
 	for _, option := range options {
@@ -20,372 +26,336 @@ func Wrap(c *pgocache.Cache, options ...TraceOption) *Wrapper {
 	} else {
 		o.DefaultAttributes = append(o.DefaultAttributes, trace.StringAttribute("cache.instance", o.InstanceName))
 	}
-	return &Wrapper{
-		Cache:   c,
-		options: o,
+	if o.Instrumenter == nil {
+		di := NewDefaultInstrumenter(o).(*defaultInstrumenter)
+		// the instruments of the default instrumenter are released by Close
+		o.instruments = di.options.instruments
+		o.Instrumenter = di
 	}
+	w := &Wrapper{
+		Cache:        c,
+		options:      o,
+		instrumenter: o.Instrumenter,
+		loads:        &singleflight.Group{},
+		locks:        newKeyLocks(),
+		versions:     newVersions(),
+		tags:         newTagIndex(),
+		sliding:      newSlidingKeys(),
+		negatives:    newNegatives(),
+		evictions:    newEvictions(),
+		slots:        &listenerSlots{},
+		namespaces:   newNamespaces(),
+		watchers:     newWatchers(),
+		capacity:     newCapacity(o),
+	}
+	if c != nil {
+		c.OnEvicted(w.evicted)
+	}
+	if o.SampleInterval > 0 {
+		w.sampler = w.startSampler(o.SampleInterval)
+	}
+	if o.Store != nil && o.WriteBehind {
+		w.writer = w.startWriteBehind()
+	}
+	return w
 }
 
 var _ Cacher = &Wrapper{}
 
 // Wrapper wraps a pgocache Cache instance with an instance name that can be used to record metrics while preforming cache methods.
 type Wrapper struct {
-	Cache   *pgocache.Cache
-	options TraceOptions
+	Cache        *pgocache.Cache
+	options      TraceOptions
+	instrumenter Instrumenter
+	loads        *singleflight.Group
+	locks        *keyLocks
+	versions     *versions
+	tags         *tagIndex
+	sliding      *slidingKeys
+	negatives    *negatives
+
+	evictions *evictions
+	slots     *listenerSlots
+	watchers  *watchers
+	capacity  *capacity
+	sampler   *sampler
+	writer    *writeBehind
+
+	// prefix is prepended to the keys of a namespace, namespaces holds those created from this Wrapper
+	prefix     string
+	namespaces *namespaces
 }
 
 // Add implementes the pggocache add method with metrics
 func (w *Wrapper) Add(ctx context.Context, k string, x interface{}, d time.Duration) (err error) {
-	if AllowTrace(ctx, w.options.Add, w.options.AllowRoot) {
-		span := StartSpan(ctx, "go.cache.add", w.options)
-		if span != nil {
-			defer func() {
-				span.EndSpanWithErr(err)
-			}()
-		}
-	}
-	var statsFunc = recordCallErrorStatus(ctx, "go.cache.add", w.options.InstanceName)
+	ctx, end := w.startKeyOp(ctx, "go.cache.add", w.options.Add, k, x)
 	defer func() {
-		statsFunc(err)
+		end(w.storedResult(ErrorResult(err), err, d))
 	}()
 
-	err = w.Cache.Add(k, x, d)
+	k, d = w.key(k), w.jitter(d)
+	err = w.add(ctx, k, x, d)
 
 	return
 }
 
 // Decrement implementes the pggocache decrement method with metrics
 func (w *Wrapper) Decrement(ctx context.Context, k string, n int64) (err error) {
-	if AllowTrace(ctx, w.options.Decrement, w.options.AllowRoot) {
-		span := StartSpan(ctx, "go.cache.decrement", w.options)
-		if span != nil {
-			defer func() {
-				span.EndSpanWithErr(err)
-			}()
-		}
-	}
-	var statsFunc = recordCallErrorStatus(ctx, "go.cache.decrement", w.options.InstanceName)
+	ctx, end := w.startKeyOp(ctx, "go.cache.decrement", w.options.Decrement, k, nil)
 	defer func() {
-		statsFunc(err)
+		end(ErrorResult(err))
 	}()
 
+	k = w.key(k)
+	unlock := w.locks.lock(k)
 	err = w.Cache.Decrement(k, n)
+	err = w.incremented(ctx, k, err)
+	unlock()
 
 	return
 }
 
 // DecrementFloat implements the pggocache decrementfloat method with metrics
 func (w *Wrapper) DecrementFloat(ctx context.Context, k string, n float64) (err error) {
-	if AllowTrace(ctx, w.options.DecrementFloat, w.options.AllowRoot) {
-		span := StartSpan(ctx, "go.cache.decrementfloat", w.options)
-		if span != nil {
-			defer func() {
-				span.EndSpanWithErr(err)
-			}()
-		}
-	}
-	var statsFunc = recordCallErrorStatus(ctx, "go.cache.decrementfloat", w.options.InstanceName)
+	ctx, end := w.startKeyOp(ctx, "go.cache.decrementfloat", w.options.DecrementFloat, k, nil)
 	defer func() {
-		statsFunc(err)
+		end(ErrorResult(err))
 	}()
 
+	k = w.key(k)
+	unlock := w.locks.lock(k)
 	err = w.Cache.DecrementFloat(k, n)
+	err = w.incremented(ctx, k, err)
+	unlock()
 
 	return
 }
 
 // DecrementFloat32 implments pggocache decremnetfloat32 method with metrics
 func (w *Wrapper) DecrementFloat32(ctx context.Context, k string, n float32) (v float32, err error) {
-	if AllowTrace(ctx, w.options.DecrementFloat32, w.options.AllowRoot) {
-		span := StartSpan(ctx, "go.cache.decrementfloat32", w.options)
-		if span != nil {
-			defer func() {
-				span.EndSpanWithErr(err)
-			}()
-		}
-	}
-	var statsFunc = recordCallErrorStatus(ctx, "go.cache.decrementfloat32", w.options.InstanceName)
+	ctx, end := w.startKeyOp(ctx, "go.cache.decrementfloat32", w.options.DecrementFloat32, k, nil)
 	defer func() {
-		statsFunc(err)
+		end(ErrorResult(err))
 	}()
 
+	k = w.key(k)
+	unlock := w.locks.lock(k)
 	v, err = w.Cache.DecrementFloat32(k, n)
+	err = w.incremented(ctx, k, err)
+	unlock()
 
 	return
 }
 
 // DecrementFloat64 implments pggocache decremnetfloat64 method with metrics
 func (w *Wrapper) DecrementFloat64(ctx context.Context, k string, n float64) (v float64, err error) {
-	if AllowTrace(ctx, w.options.DecrementFloat64, w.options.AllowRoot) {
-		span := StartSpan(ctx, "go.cache.decrementfloat64", w.options)
-		if span != nil {
-			defer func() {
-				span.EndSpanWithErr(err)
-			}()
-		}
-	}
-	var statsFunc = recordCallErrorStatus(ctx, "go.cache.decrementfloat64", w.options.InstanceName)
+	ctx, end := w.startKeyOp(ctx, "go.cache.decrementfloat64", w.options.DecrementFloat64, k, nil)
 	defer func() {
-		statsFunc(err)
+		end(ErrorResult(err))
 	}()
 
+	k = w.key(k)
+	unlock := w.locks.lock(k)
 	v, err = w.Cache.DecrementFloat64(k, n)
+	err = w.incremented(ctx, k, err)
+	unlock()
 
 	return
 }
 
 // DecrementInt implments pggocache decremnetint method with metrics
 func (w *Wrapper) DecrementInt(ctx context.Context, k string, n int) (v int, err error) {
-	if AllowTrace(ctx, w.options.DecrementInt, w.options.AllowRoot) {
-		span := StartSpan(ctx, "go.cache.decrementint", w.options)
-		if span != nil {
-			defer func() {
-				span.EndSpanWithErr(err)
-			}()
-		}
-	}
-	var statsFunc = recordCallErrorStatus(ctx, "go.cache.decrementint", w.options.InstanceName)
+	ctx, end := w.startKeyOp(ctx, "go.cache.decrementint", w.options.DecrementInt, k, nil)
 	defer func() {
-		statsFunc(err)
+		end(ErrorResult(err))
 	}()
 
+	k = w.key(k)
+	unlock := w.locks.lock(k)
 	v, err = w.Cache.DecrementInt(k, n)
+	err = w.incremented(ctx, k, err)
+	unlock()
 
 	return
 }
 
 // DecrementInt16 implments pggocache decremnetint16 method with metrics
 func (w *Wrapper) DecrementInt16(ctx context.Context, k string, n int16) (v int16, err error) {
-	if AllowTrace(ctx, w.options.DecrementInt16, w.options.AllowRoot) {
-		span := StartSpan(ctx, "go.cache.decrementint16", w.options)
-		if span != nil {
-			defer func() {
-				span.EndSpanWithErr(err)
-			}()
-		}
-	}
-	var statsFunc = recordCallErrorStatus(ctx, "go.cache.decrementint16", w.options.InstanceName)
+	ctx, end := w.startKeyOp(ctx, "go.cache.decrementint16", w.options.DecrementInt16, k, nil)
 	defer func() {
-		statsFunc(err)
+		end(ErrorResult(err))
 	}()
 
+	k = w.key(k)
+	unlock := w.locks.lock(k)
 	v, err = w.Cache.DecrementInt16(k, n)
+	err = w.incremented(ctx, k, err)
+	unlock()
 
 	return
 }
 
 // DecrementInt32 implments pggocache decremnetint32 method with metrics
 func (w *Wrapper) DecrementInt32(ctx context.Context, k string, n int32) (v int32, err error) {
-	if AllowTrace(ctx, w.options.DecrementInt32, w.options.AllowRoot) {
-		span := StartSpan(ctx, "go.cache.decrementint32", w.options)
-		if span != nil {
-			defer func() {
-				span.EndSpanWithErr(err)
-			}()
-		}
-	}
-	var statsFunc = recordCallErrorStatus(ctx, "go.cache.decrementint32", w.options.InstanceName)
+	ctx, end := w.startKeyOp(ctx, "go.cache.decrementint32", w.options.DecrementInt32, k, nil)
 	defer func() {
-		statsFunc(err)
+		end(ErrorResult(err))
 	}()
 
+	k = w.key(k)
+	unlock := w.locks.lock(k)
 	v, err = w.Cache.DecrementInt32(k, n)
+	err = w.incremented(ctx, k, err)
+	unlock()
 
 	return
 }
 
 // DecrementInt64 implments pggocache decremnetint64 method with metrics
 func (w *Wrapper) DecrementInt64(ctx context.Context, k string, n int64) (v int64, err error) {
-	if AllowTrace(ctx, w.options.DecrementInt64, w.options.AllowRoot) {
-		span := StartSpan(ctx, "go.cache.decrementint64", w.options)
-		if span != nil {
-			defer func() {
-				span.EndSpanWithErr(err)
-			}()
-		}
-	}
-	var statsFunc = recordCallErrorStatus(ctx, "go.cache.decrementint64", w.options.InstanceName)
+	ctx, end := w.startKeyOp(ctx, "go.cache.decrementint64", w.options.DecrementInt64, k, nil)
 	defer func() {
-		statsFunc(err)
+		end(ErrorResult(err))
 	}()
 
+	k = w.key(k)
+	unlock := w.locks.lock(k)
 	v, err = w.Cache.DecrementInt64(k, n)
+	err = w.incremented(ctx, k, err)
+	unlock()
 
 	return
 }
 
 // DecrementInt8 implments pggocache decremnetint8 method with metrics
 func (w *Wrapper) DecrementInt8(ctx context.Context, k string, n int8) (v int8, err error) {
-	if AllowTrace(ctx, w.options.DecrementInt8, w.options.AllowRoot) {
-		span := StartSpan(ctx, "go.cache.decrementint8", w.options)
-		if span != nil {
-			defer func() {
-				span.EndSpanWithErr(err)
-			}()
-		}
-	}
-	var statsFunc = recordCallErrorStatus(ctx, "go.cache.decrementint8", w.options.InstanceName)
+	ctx, end := w.startKeyOp(ctx, "go.cache.decrementint8", w.options.DecrementInt8, k, nil)
 	defer func() {
-		statsFunc(err)
+		end(ErrorResult(err))
 	}()
 
+	k = w.key(k)
+	unlock := w.locks.lock(k)
 	v, err = w.Cache.DecrementInt8(k, n)
+	err = w.incremented(ctx, k, err)
+	unlock()
 
 	return
 }
 
 // DecrementUint implments pggocache decremnetuint method with metrics
 func (w *Wrapper) DecrementUint(ctx context.Context, k string, n uint) (v uint, err error) {
-	if AllowTrace(ctx, w.options.DecrementUint, w.options.AllowRoot) {
-		span := StartSpan(ctx, "go.cache.decrementuint", w.options)
-		if span != nil {
-			defer func() {
-				span.EndSpanWithErr(err)
-			}()
-		}
-	}
-	var statsFunc = recordCallErrorStatus(ctx, "go.cache.decrementuint", w.options.InstanceName)
+	ctx, end := w.startKeyOp(ctx, "go.cache.decrementuint", w.options.DecrementUint, k, nil)
 	defer func() {
-		statsFunc(err)
+		end(ErrorResult(err))
 	}()
 
+	k = w.key(k)
+	unlock := w.locks.lock(k)
 	v, err = w.Cache.DecrementUint(k, n)
+	err = w.incremented(ctx, k, err)
+	unlock()
 
 	return
 }
 
 // DecrementUint16 implments pggocache decremnetuint16 method with metrics
 func (w *Wrapper) DecrementUint16(ctx context.Context, k string, n uint16) (v uint16, err error) {
-	if AllowTrace(ctx, w.options.DecrementUint16, w.options.AllowRoot) {
-		span := StartSpan(ctx, "go.cache.decrementuint16", w.options)
-		if span != nil {
-			defer func() {
-				span.EndSpanWithErr(err)
-			}()
-		}
-	}
-	var statsFunc = recordCallErrorStatus(ctx, "go.cache.decrementuint16", w.options.InstanceName)
+	ctx, end := w.startKeyOp(ctx, "go.cache.decrementuint16", w.options.DecrementUint16, k, nil)
 	defer func() {
-		statsFunc(err)
+		end(ErrorResult(err))
 	}()
 
+	k = w.key(k)
+	unlock := w.locks.lock(k)
 	v, err = w.Cache.DecrementUint16(k, n)
+	err = w.incremented(ctx, k, err)
+	unlock()
 
 	return
 }
 
 // DecrementUint32 implments pggocache decremnetuint32 method with metrics
 func (w *Wrapper) DecrementUint32(ctx context.Context, k string, n uint32) (v uint32, err error) {
-	if AllowTrace(ctx, w.options.DecrementUint32, w.options.AllowRoot) {
-		span := StartSpan(ctx, "go.cache.decrementuint32", w.options)
-		if span != nil {
-			defer func() {
-				span.EndSpanWithErr(err)
-			}()
-		}
-	}
-	var statsFunc = recordCallErrorStatus(ctx, "go.cache.decrementuint32", w.options.InstanceName)
+	ctx, end := w.startKeyOp(ctx, "go.cache.decrementuint32", w.options.DecrementUint32, k, nil)
 	defer func() {
-		statsFunc(err)
+		end(ErrorResult(err))
 	}()
 
+	k = w.key(k)
+	unlock := w.locks.lock(k)
 	v, err = w.Cache.DecrementUint32(k, n)
+	err = w.incremented(ctx, k, err)
+	unlock()
 
 	return
 }
 
 // DecrementUint64 implments pggocache decremnetuint64 method with metrics
 func (w *Wrapper) DecrementUint64(ctx context.Context, k string, n uint64) (v uint64, err error) {
-	if AllowTrace(ctx, w.options.DecrementUint64, w.options.AllowRoot) {
-		span := StartSpan(ctx, "go.cache.decrementuint64", w.options)
-		if span != nil {
-			defer func() {
-				span.EndSpanWithErr(err)
-			}()
-		}
-	}
-	var statsFunc = recordCallErrorStatus(ctx, "go.cache.decrementuint64", w.options.InstanceName)
+	ctx, end := w.startKeyOp(ctx, "go.cache.decrementuint64", w.options.DecrementUint64, k, nil)
 	defer func() {
-		statsFunc(err)
+		end(ErrorResult(err))
 	}()
 
+	k = w.key(k)
+	unlock := w.locks.lock(k)
 	v, err = w.Cache.DecrementUint64(k, n)
+	err = w.incremented(ctx, k, err)
+	unlock()
 
 	return
 }
 
 // DecrementUint8 implments pggocache decremnetUint8 method with metrics
 func (w *Wrapper) DecrementUint8(ctx context.Context, k string, n uint8) (v uint8, err error) {
-	if AllowTrace(ctx, w.options.DecrementUint8, w.options.AllowRoot) {
-		span := StartSpan(ctx, "go.cache.decrementuint8", w.options)
-		if span != nil {
-			defer func() {
-				span.EndSpanWithErr(err)
-			}()
-		}
-	}
-	var statsFunc = recordCallErrorStatus(ctx, "go.cache.decrementuint8", w.options.InstanceName)
+	ctx, end := w.startKeyOp(ctx, "go.cache.decrementuint8", w.options.DecrementUint8, k, nil)
 	defer func() {
-		statsFunc(err)
+		end(ErrorResult(err))
 	}()
 
+	k = w.key(k)
+	unlock := w.locks.lock(k)
 	v, err = w.Cache.DecrementUint8(k, n)
+	err = w.incremented(ctx, k, err)
+	unlock()
 
 	return
 }
 
 // DecrementUintptr implments pggocache decremnetuintptr method with metrics
 func (w *Wrapper) DecrementUintptr(ctx context.Context, k string, n uintptr) (v uintptr, err error) {
-	if AllowTrace(ctx, w.options.DecrementUintptr, w.options.AllowRoot) {
-		span := StartSpan(ctx, "go.cache.decrementuintptr", w.options)
-		if span != nil {
-			defer func() {
-				span.EndSpanWithErr(err)
-			}()
-		}
-	}
-	var statsFunc = recordCallErrorStatus(ctx, "go.cache.decrementuintptr", w.options.InstanceName)
+	ctx, end := w.startKeyOp(ctx, "go.cache.decrementuintptr", w.options.DecrementUintptr, k, nil)
 	defer func() {
-		statsFunc(err)
+		end(ErrorResult(err))
 	}()
 
+	k = w.key(k)
+	unlock := w.locks.lock(k)
 	v, err = w.Cache.DecrementUintptr(k, n)
+	err = w.incremented(ctx, k, err)
+	unlock()
 
 	return
 }
 
 // Delete implments pggocache delete method with metrics
 func (w *Wrapper) Delete(ctx context.Context, k string) {
-	if AllowTrace(ctx, w.options.Delete, w.options.AllowRoot) {
-		span := StartSpan(ctx, "go.cache.delete", w.options)
-		if span != nil {
-			defer func() {
-				span.EndSpan()
-			}()
-		}
-	}
-	var statsFunc = recordCallStats(ctx, "go.cache.delete", w.options.InstanceName)
+	var err error
+	ctx, end := w.startKeyOp(ctx, "go.cache.delete", w.options.Delete, k, nil)
 	defer func() {
-		statsFunc()
+		end(writeResult(err))
 	}()
 
-	w.Cache.Delete(k)
-
+	k = w.key(k)
+	_, err = w.remove(ctx, k, EvictionReasonDeleted)
 }
 
 // DeleteExpired implments pggocache deleteexpired method with metrics
 func (w *Wrapper) DeleteExpired(ctx context.Context) {
-	if AllowTrace(ctx, w.options.DeleteExpired, w.options.AllowRoot) {
-		span := StartSpan(ctx, "go.cache.deleteexpired", w.options)
-		if span != nil {
-			defer func() {
-				span.EndSpan()
-			}()
-		}
-	}
-	var statsFunc = recordCallStats(ctx, "go.cache.deleteexpired", w.options.InstanceName)
+	ctx, end := w.startOp(ctx, "go.cache.deleteexpired", w.options.DeleteExpired)
 	defer func() {
-		statsFunc()
+		end(CalledResult())
 	}()
 
 	w.Cache.DeleteExpired()
@@ -394,416 +364,313 @@ func (w *Wrapper) DeleteExpired(ctx context.Context) {
 
 // Flush implments pggocache flush method with metrics
 func (w *Wrapper) Flush(ctx context.Context) {
-	if AllowTrace(ctx, w.options.Flush, w.options.AllowRoot) {
-		span := StartSpan(ctx, "go.cache.flush", w.options)
-		if span != nil {
-			defer func() {
-				span.EndSpan()
-			}()
-		}
-	}
-	var statsFunc = recordCallStats(ctx, "go.cache.flush", w.options.InstanceName)
+	ctx, end := w.startOp(ctx, "go.cache.flush", w.options.Flush)
 	defer func() {
-		statsFunc()
+		end(CalledResult())
 	}()
 
-	w.Cache.Flush()
-
+	w.flush(ctx)
 }
 
 // Get implments pggocache get method with metrics
 func (w *Wrapper) Get(ctx context.Context, k string) (v interface{}, found bool) {
-	if AllowTrace(ctx, w.options.Get, w.options.AllowRoot) {
-		span := StartSpan(ctx, "go.cache.get", w.options)
-		if span != nil {
-			defer func() {
-				span.EndSpan()
-			}()
-		}
-	}
-	var statsFunc = recordCallFoundStats(ctx, "go.cache.get", w.options.InstanceName)
+	ctx, end := w.startKeyOp(ctx, "go.cache.get", w.options.Get, k, nil)
 	defer func() {
-		statsFunc(found)
+		end(lookupResult(v, found))
 	}()
 
-	v, found = w.Cache.Get(k)
+	k = w.key(k)
+	if v, _, found = w.get(ctx, "go.cache.get", k); !found && !IsAbsent(v) && w.readThrough(ctx, k) {
+		v, _, found = w.get(ctx, "go.cache.get", k)
+	}
 
 	return
 }
 
 // GetWithExpiration implments pggocache getwithexpiration method with metrics
 func (w *Wrapper) GetWithExpiration(ctx context.Context, k string) (v interface{}, exp time.Time, found bool) {
-	if AllowTrace(ctx, w.options.GetWithExpiration, w.options.AllowRoot) {
-		span := StartSpan(ctx, "go.cache.getwithexpiration", w.options)
-		if span != nil {
-			defer func() {
-				span.EndSpan()
-			}()
-		}
-	}
-	var statsFunc = recordCallFoundStats(ctx, "go.cache.getwithexpiration", w.options.InstanceName)
+	ctx, end := w.startKeyOp(ctx, "go.cache.getwithexpiration", w.options.GetWithExpiration, k, nil)
 	defer func() {
-		statsFunc(found)
+		end(lookupResult(v, found))
 	}()
 
-	v, exp, found = w.Cache.GetWithExpiration(k)
+	k = w.key(k)
+	if v, exp, found = w.get(ctx, "go.cache.getwithexpiration", k); !found && !IsAbsent(v) && w.readThrough(ctx, k) {
+		v, exp, found = w.get(ctx, "go.cache.getwithexpiration", k)
+	}
 
 	return
 }
 
 // Increment implments pggocache increment method with metrics
 func (w *Wrapper) Increment(ctx context.Context, k string, n int64) (err error) {
-	if AllowTrace(ctx, w.options.Increment, w.options.AllowRoot) {
-		span := StartSpan(ctx, "go.cache.increment", w.options)
-		if span != nil {
-			defer func() {
-				span.EndSpanWithErr(err)
-			}()
-		}
-	}
-	var statsFunc = recordCallErrorStatus(ctx, "go.cache.increment", w.options.InstanceName)
+	ctx, end := w.startKeyOp(ctx, "go.cache.increment", w.options.Increment, k, nil)
 	defer func() {
-		statsFunc(err)
+		end(ErrorResult(err))
 	}()
 
+	k = w.key(k)
+	unlock := w.locks.lock(k)
 	err = w.Cache.Increment(k, n)
+	err = w.incremented(ctx, k, err)
+	unlock()
 
 	return
 }
 
 // IncrementFloat implments pggocache incrementfloat method with metrics
 func (w *Wrapper) IncrementFloat(ctx context.Context, k string, n float64) (err error) {
-	if AllowTrace(ctx, w.options.IncrementFloat, w.options.AllowRoot) {
-		span := StartSpan(ctx, "go.cache.incrementfloat", w.options)
-		if span != nil {
-			defer func() {
-				span.EndSpanWithErr(err)
-			}()
-		}
-	}
-	var statsFunc = recordCallErrorStatus(ctx, "go.cache.incrementfloat", w.options.InstanceName)
+	ctx, end := w.startKeyOp(ctx, "go.cache.incrementfloat", w.options.IncrementFloat, k, nil)
 	defer func() {
-		statsFunc(err)
+		end(ErrorResult(err))
 	}()
 
+	k = w.key(k)
+	unlock := w.locks.lock(k)
 	err = w.Cache.IncrementFloat(k, n)
+	err = w.incremented(ctx, k, err)
+	unlock()
 
 	return
 }
 
 // IncrementFloat32 implments pggocache incrementfloat32 method with metrics
 func (w *Wrapper) IncrementFloat32(ctx context.Context, k string, n float32) (v float32, err error) {
-	if AllowTrace(ctx, w.options.IncrementFloat32, w.options.AllowRoot) {
-		span := StartSpan(ctx, "go.cache.incrementfloat32", w.options)
-		if span != nil {
-			defer func() {
-				span.EndSpanWithErr(err)
-			}()
-		}
-	}
-	var statsFunc = recordCallErrorStatus(ctx, "go.cache.incrementfloat32", w.options.InstanceName)
+	ctx, end := w.startKeyOp(ctx, "go.cache.incrementfloat32", w.options.IncrementFloat32, k, nil)
 	defer func() {
-		statsFunc(err)
+		end(ErrorResult(err))
 	}()
 
+	k = w.key(k)
+	unlock := w.locks.lock(k)
 	v, err = w.Cache.IncrementFloat32(k, n)
+	err = w.incremented(ctx, k, err)
+	unlock()
 
 	return
 }
 
 // IncrementFloat64 implments pggocache incrementfloat64 method with metrics
 func (w *Wrapper) IncrementFloat64(ctx context.Context, k string, n float64) (v float64, err error) {
-	if AllowTrace(ctx, w.options.IncrementFloat64, w.options.AllowRoot) {
-		span := StartSpan(ctx, "go.cache.incrementfloat64", w.options)
-		if span != nil {
-			defer func() {
-				span.EndSpanWithErr(err)
-			}()
-		}
-	}
-	var statsFunc = recordCallErrorStatus(ctx, "go.cache.incrementfloat64", w.options.InstanceName)
+	ctx, end := w.startKeyOp(ctx, "go.cache.incrementfloat64", w.options.IncrementFloat64, k, nil)
 	defer func() {
-		statsFunc(err)
+		end(ErrorResult(err))
 	}()
 
+	k = w.key(k)
+	unlock := w.locks.lock(k)
 	v, err = w.Cache.IncrementFloat64(k, n)
+	err = w.incremented(ctx, k, err)
+	unlock()
 
 	return
 }
 
 // IncrementInt implments pggocache incrementint method with metrics
 func (w *Wrapper) IncrementInt(ctx context.Context, k string, n int) (v int, err error) {
-	if AllowTrace(ctx, w.options.IncrementInt, w.options.AllowRoot) {
-		span := StartSpan(ctx, "go.cache.incrementint", w.options)
-		if span != nil {
-			defer func() {
-				span.EndSpanWithErr(err)
-			}()
-		}
-	}
-	var statsFunc = recordCallErrorStatus(ctx, "go.cache.incrementint", w.options.InstanceName)
+	ctx, end := w.startKeyOp(ctx, "go.cache.incrementint", w.options.IncrementInt, k, nil)
 	defer func() {
-		statsFunc(err)
+		end(ErrorResult(err))
 	}()
 
+	k = w.key(k)
+	unlock := w.locks.lock(k)
 	v, err = w.Cache.IncrementInt(k, n)
+	err = w.incremented(ctx, k, err)
+	unlock()
 
 	return
 }
 
 // IncrementInt16 implments pggocache incrementint16 method with metrics
 func (w *Wrapper) IncrementInt16(ctx context.Context, k string, n int16) (v int16, err error) {
-	if AllowTrace(ctx, w.options.IncrementInt16, w.options.AllowRoot) {
-		span := StartSpan(ctx, "go.cache.incrementint16", w.options)
-		if span != nil {
-			defer func() {
-				span.EndSpanWithErr(err)
-			}()
-		}
-	}
-	var statsFunc = recordCallErrorStatus(ctx, "go.cache.incrementint16", w.options.InstanceName)
+	ctx, end := w.startKeyOp(ctx, "go.cache.incrementint16", w.options.IncrementInt16, k, nil)
 	defer func() {
-		statsFunc(err)
+		end(ErrorResult(err))
 	}()
 
+	k = w.key(k)
+	unlock := w.locks.lock(k)
 	v, err = w.Cache.IncrementInt16(k, n)
+	err = w.incremented(ctx, k, err)
+	unlock()
 
 	return
 }
 
 // IncrementInt32 implments pggocache incrementint32 method with metrics
 func (w *Wrapper) IncrementInt32(ctx context.Context, k string, n int32) (v int32, err error) {
-	if AllowTrace(ctx, w.options.IncrementInt32, w.options.AllowRoot) {
-		span := StartSpan(ctx, "go.cache.incrementint32", w.options)
-		if span != nil {
-			defer func() {
-				span.EndSpanWithErr(err)
-			}()
-		}
-	}
-	var statsFunc = recordCallErrorStatus(ctx, "go.cache.incrementint32", w.options.InstanceName)
+	ctx, end := w.startKeyOp(ctx, "go.cache.incrementint32", w.options.IncrementInt32, k, nil)
 	defer func() {
-		statsFunc(err)
+		end(ErrorResult(err))
 	}()
 
+	k = w.key(k)
+	unlock := w.locks.lock(k)
 	v, err = w.Cache.IncrementInt32(k, n)
+	err = w.incremented(ctx, k, err)
+	unlock()
 
 	return
 }
 
 // IncrementInt64 implments pggocache incrementint64 method with metrics
 func (w *Wrapper) IncrementInt64(ctx context.Context, k string, n int64) (v int64, err error) {
-	if AllowTrace(ctx, w.options.IncrementInt64, w.options.AllowRoot) {
-		span := StartSpan(ctx, "go.cache.incrementint64", w.options)
-		if span != nil {
-			defer func() {
-				span.EndSpanWithErr(err)
-			}()
-		}
-	}
-	var statsFunc = recordCallErrorStatus(ctx, "go.cache.incrementint64", w.options.InstanceName)
+	ctx, end := w.startKeyOp(ctx, "go.cache.incrementint64", w.options.IncrementInt64, k, nil)
 	defer func() {
-		statsFunc(err)
+		end(ErrorResult(err))
 	}()
 
+	k = w.key(k)
+	unlock := w.locks.lock(k)
 	v, err = w.Cache.IncrementInt64(k, n)
+	err = w.incremented(ctx, k, err)
+	unlock()
 
 	return
 }
 
 // IncrementInt8 implments pggocache incrementint8 method with metrics
 func (w *Wrapper) IncrementInt8(ctx context.Context, k string, n int8) (v int8, err error) {
-	if AllowTrace(ctx, w.options.IncrementInt8, w.options.AllowRoot) {
-		span := StartSpan(ctx, "go.cache.incrementint8", w.options)
-		if span != nil {
-			defer func() {
-				span.EndSpanWithErr(err)
-			}()
-		}
-	}
-	var statsFunc = recordCallErrorStatus(ctx, "go.cache.incrementint8", w.options.InstanceName)
+	ctx, end := w.startKeyOp(ctx, "go.cache.incrementint8", w.options.IncrementInt8, k, nil)
 	defer func() {
-		statsFunc(err)
+		end(ErrorResult(err))
 	}()
 
+	k = w.key(k)
+	unlock := w.locks.lock(k)
 	v, err = w.Cache.IncrementInt8(k, n)
+	err = w.incremented(ctx, k, err)
+	unlock()
 
 	return
 }
 
 // IncrementUint implments pggocache incrementuint method with metrics
 func (w *Wrapper) IncrementUint(ctx context.Context, k string, n uint) (v uint, err error) {
-	if AllowTrace(ctx, w.options.IncrementUint, w.options.AllowRoot) {
-		span := StartSpan(ctx, "go.cache.incrementuint", w.options)
-		if span != nil {
-			defer func() {
-				span.EndSpanWithErr(err)
-			}()
-		}
-	}
-	var statsFunc = recordCallErrorStatus(ctx, "go.cache.incrementuint", w.options.InstanceName)
+	ctx, end := w.startKeyOp(ctx, "go.cache.incrementuint", w.options.IncrementUint, k, nil)
 	defer func() {
-		statsFunc(err)
+		end(ErrorResult(err))
 	}()
 
+	k = w.key(k)
+	unlock := w.locks.lock(k)
 	v, err = w.Cache.IncrementUint(k, n)
+	err = w.incremented(ctx, k, err)
+	unlock()
 
 	return
 }
 
 // IncrementUint16 implments pggocache incrementuint16 method with metrics
 func (w *Wrapper) IncrementUint16(ctx context.Context, k string, n uint16) (v uint16, err error) {
-	if AllowTrace(ctx, w.options.IncrementUint16, w.options.AllowRoot) {
-		span := StartSpan(ctx, "go.cache.incrementuint16", w.options)
-		if span != nil {
-			defer func() {
-				span.EndSpanWithErr(err)
-			}()
-		}
-	}
-	var statsFunc = recordCallErrorStatus(ctx, "go.cache.incrementuint16", w.options.InstanceName)
+	ctx, end := w.startKeyOp(ctx, "go.cache.incrementuint16", w.options.IncrementUint16, k, nil)
 	defer func() {
-		statsFunc(err)
+		end(ErrorResult(err))
 	}()
 
+	k = w.key(k)
+	unlock := w.locks.lock(k)
 	v, err = w.Cache.IncrementUint16(k, n)
+	err = w.incremented(ctx, k, err)
+	unlock()
 
 	return
 }
 
 // IncrementUint32 implments pggocache incrementuint32 method with metrics
 func (w *Wrapper) IncrementUint32(ctx context.Context, k string, n uint32) (v uint32, err error) {
-	if AllowTrace(ctx, w.options.IncrementUint32, w.options.AllowRoot) {
-		span := StartSpan(ctx, "go.cache.incrementuint32", w.options)
-		if span != nil {
-			defer func() {
-				span.EndSpanWithErr(err)
-			}()
-		}
-	}
-	var statsFunc = recordCallErrorStatus(ctx, "go.cache.incrementuint32", w.options.InstanceName)
+	ctx, end := w.startKeyOp(ctx, "go.cache.incrementuint32", w.options.IncrementUint32, k, nil)
 	defer func() {
-		statsFunc(err)
+		end(ErrorResult(err))
 	}()
 
+	k = w.key(k)
+	unlock := w.locks.lock(k)
 	v, err = w.Cache.IncrementUint32(k, n)
+	err = w.incremented(ctx, k, err)
+	unlock()
 
 	return
 }
 
 // IncrementUint64 implments pggocache incrementuint64 method with metrics
 func (w *Wrapper) IncrementUint64(ctx context.Context, k string, n uint64) (v uint64, err error) {
-	if AllowTrace(ctx, w.options.IncrementUint64, w.options.AllowRoot) {
-		span := StartSpan(ctx, "go.cache.incrementuint64", w.options)
-		if span != nil {
-			defer func() {
-				span.EndSpanWithErr(err)
-			}()
-		}
-	}
-	var statsFunc = recordCallErrorStatus(ctx, "go.cache.incrementuint64", w.options.InstanceName)
+	ctx, end := w.startKeyOp(ctx, "go.cache.incrementuint64", w.options.IncrementUint64, k, nil)
 	defer func() {
-		statsFunc(err)
+		end(ErrorResult(err))
 	}()
 
+	k = w.key(k)
+	unlock := w.locks.lock(k)
 	v, err = w.Cache.IncrementUint64(k, n)
+	err = w.incremented(ctx, k, err)
+	unlock()
 
 	return
 }
 
 // IncrementUint8 implments pggocache incrementuint8 method with metrics
 func (w *Wrapper) IncrementUint8(ctx context.Context, k string, n uint8) (v uint8, err error) {
-	if AllowTrace(ctx, w.options.IncrementUint8, w.options.AllowRoot) {
-		span := StartSpan(ctx, "go.cache.incrementuint8", w.options)
-		if span != nil {
-			defer func() {
-				span.EndSpanWithErr(err)
-			}()
-		}
-	}
-	var statsFunc = recordCallErrorStatus(ctx, "go.cache.incrementuint8", w.options.InstanceName)
+	ctx, end := w.startKeyOp(ctx, "go.cache.incrementuint8", w.options.IncrementUint8, k, nil)
 	defer func() {
-		statsFunc(err)
+		end(ErrorResult(err))
 	}()
 
+	k = w.key(k)
+	unlock := w.locks.lock(k)
 	v, err = w.Cache.IncrementUint8(k, n)
+	err = w.incremented(ctx, k, err)
+	unlock()
 
 	return
 }
 
 // IncrementUintptr implments pggocache incrementuintptr method with metrics
 func (w *Wrapper) IncrementUintptr(ctx context.Context, k string, n uintptr) (v uintptr, err error) {
-	if AllowTrace(ctx, w.options.IncrementUintptr, w.options.AllowRoot) {
-		span := StartSpan(ctx, "go.cache.incrementuintptr", w.options)
-		if span != nil {
-			defer func() {
-				span.EndSpanWithErr(err)
-			}()
-		}
-	}
-	var statsFunc = recordCallErrorStatus(ctx, "go.cache.incrementuintptr", w.options.InstanceName)
+	ctx, end := w.startKeyOp(ctx, "go.cache.incrementuintptr", w.options.IncrementUintptr, k, nil)
 	defer func() {
-		statsFunc(err)
+		end(ErrorResult(err))
 	}()
 
+	k = w.key(k)
+	unlock := w.locks.lock(k)
 	v, err = w.Cache.IncrementUintptr(k, n)
+	err = w.incremented(ctx, k, err)
+	unlock()
 
 	return
 }
 
 // ItemCount implments pggocache itemcount method with metrics
 func (w *Wrapper) ItemCount(ctx context.Context) (c int) {
-	if AllowTrace(ctx, w.options.ItemCount, w.options.AllowRoot) {
-		span := StartSpan(ctx, "go.cache.itemcount", w.options)
-		if span != nil {
-			defer func() {
-				span.EndSpan()
-			}()
-		}
-	}
-	var statsFunc = recordCallStats(ctx, "go.cache.itemcount", w.options.InstanceName)
+	ctx, end := w.startOp(ctx, "go.cache.itemcount", w.options.ItemCount)
 	defer func() {
-		statsFunc()
+		end(CalledResult())
 	}()
 
-	c = w.Cache.ItemCount()
+	c = w.itemCount()
 
 	return
 }
 
 // Items implments pggocache items method with metrics
 func (w *Wrapper) Items(ctx context.Context) (items map[string]pgocache.Item) {
-	if AllowTrace(ctx, w.options.Items, w.options.AllowRoot) {
-		span := StartSpan(ctx, "go.cache.items", w.options)
-		if span != nil {
-			defer func() {
-				span.EndSpan()
-			}()
-		}
-	}
-	var statsFunc = recordCallStats(ctx, "go.cache.items", w.options.InstanceName)
+	ctx, end := w.startOp(ctx, "go.cache.items", w.options.Items)
 	defer func() {
-		statsFunc()
+		end(CalledResult())
 	}()
 
-	items = w.Cache.Items()
+	items = w.items()
 
 	return
 }
 
 // Load implments pggocache load method with metrics
 func (w *Wrapper) Load(ctx context.Context, r io.Reader) (err error) {
-	if AllowTrace(ctx, w.options.Load, w.options.AllowRoot) {
-		span := StartSpan(ctx, "go.cache.load", w.options)
-		if span != nil {
-			defer func() {
-				span.EndSpanWithErr(err)
-			}()
-		}
-	}
-	var statsFunc = recordCallErrorStatus(ctx, "go.cache.load", w.options.InstanceName)
+	ctx, end := w.startOp(ctx, "go.cache.load", w.options.Load)
 	defer func() {
-		statsFunc(err)
+		end(ErrorResult(err))
 	}()
 
 	err = w.Cache.Load(r)
@@ -813,17 +680,9 @@ func (w *Wrapper) Load(ctx context.Context, r io.Reader) (err error) {
 
 // LoadFile implments pggocache loadfile method with metrics
 func (w *Wrapper) LoadFile(ctx context.Context, fname string) (err error) {
-	if AllowTrace(ctx, w.options.LoadFile, w.options.AllowRoot) {
-		span := StartSpan(ctx, "go.cache.loadfile", w.options)
-		if span != nil {
-			defer func() {
-				span.EndSpanWithErr(err)
-			}()
-		}
-	}
-	var statsFunc = recordCallErrorStatus(ctx, "go.cache.loadfile", w.options.InstanceName)
+	ctx, end := w.startOp(ctx, "go.cache.loadfile", w.options.LoadFile)
 	defer func() {
-		statsFunc(err)
+		end(ErrorResult(err))
 	}()
 
 	err = w.Cache.LoadFile(fname)
@@ -833,55 +692,69 @@ func (w *Wrapper) LoadFile(ctx context.Context, fname string) (err error) {
 
 // OnEvicted implments pggocache onevicted method with metrics
 func (w *Wrapper) OnEvicted(ctx context.Context, f func(string, interface{})) {
-	if AllowTrace(ctx, w.options.OnEvicted, w.options.AllowRoot) {
-		span := StartSpan(ctx, "go.cache.onevicted", w.options)
-		if span != nil {
-			defer func() {
-				span.EndSpan()
-			}()
-		}
-	}
-	var statsFunc = recordCallStats(ctx, "go.cache.onevicted", w.options.InstanceName)
+	ctx, end := w.startOp(ctx, "go.cache.onevicted", w.options.OnEvicted)
+	defer func() {
+		end(CalledResult())
+	}()
+
+	w.evictions.replace(&w.slots.onEvicted, w.scoped(legacyListener(f)))
+}
+
+// OnEvictedWithReason sets a function to call with the reason whenever an item leaves the cache, including when it is
+// replaced or flushed. The context is that of the Wrapper call that removed the item, or a background context for
+// items removed by go-cache after expiring.
+func (w *Wrapper) OnEvictedWithReason(ctx context.Context, f EvictionFunc) {
+	ctx, end := w.startOp(ctx, "go.cache.onevictedwithreason", w.options.OnEvictedWithReason)
 	defer func() {
-		statsFunc()
+		end(CalledResult())
 	}()
 
-	w.Cache.OnEvicted(f)
+	w.evictions.replace(&w.slots.onEvictedWithReason, w.scoped(f))
+}
+
+// AddEvictionListener registers f to be called with the reason whenever an item leaves the cache. Unlike OnEvicted and
+// OnEvictedWithReason, listeners added this way do not replace each other and are called in the order they were
+// added. A listener that panics does not prevent the others from being called. The returned ListenerID can be passed
+// to RemoveEvictionListener.
+func (w *Wrapper) AddEvictionListener(ctx context.Context, f EvictionFunc) (id ListenerID) {
+	ctx, end := w.startOp(ctx, "go.cache.addevictionlistener", w.options.AddEvictionListener)
+	defer func() {
+		end(CalledResult())
+	}()
+
+	id = w.evictions.add(w.scoped(f))
+
+	return
+}
+
+// RemoveEvictionListener unregisters a listener added with AddEvictionListener
+func (w *Wrapper) RemoveEvictionListener(ctx context.Context, id ListenerID) {
+	ctx, end := w.startOp(ctx, "go.cache.removeevictionlistener", w.options.RemoveEvictionListener)
+	defer func() {
+		end(CalledResult())
+	}()
+
+	w.evictions.remove(id)
 }
 
 // Replace implments pggocache replace method with metrics
 func (w *Wrapper) Replace(ctx context.Context, k string, x interface{}, d time.Duration) (err error) {
-	if AllowTrace(ctx, w.options.Replace, w.options.AllowRoot) {
-		span := StartSpan(ctx, "go.cache.replace", w.options)
-		if span != nil {
-			defer func() {
-				span.EndSpanWithErr(err)
-			}()
-		}
-	}
-	var statsFunc = recordCallErrorStatus(ctx, "go.cache.replace", w.options.InstanceName)
+	ctx, end := w.startKeyOp(ctx, "go.cache.replace", w.options.Replace, k, x)
 	defer func() {
-		statsFunc(err)
+		end(w.storedResult(ErrorResult(err), err, d))
 	}()
 
-	err = w.Cache.Replace(k, x, d)
+	k, d = w.key(k), w.jitter(d)
+	err = w.replace(ctx, k, x, d)
 
 	return
 }
 
 // Save implments pggocache save method with metrics
 func (w *Wrapper) Save(ctx context.Context, wr io.Writer) (err error) {
-	if AllowTrace(ctx, w.options.Save, w.options.AllowRoot) {
-		span := StartSpan(ctx, "go.cache.save", w.options)
-		if span != nil {
-			defer func() {
-				span.EndSpanWithErr(err)
-			}()
-		}
-	}
-	var statsFunc = recordCallErrorStatus(ctx, "go.cache.save", w.options.InstanceName)
+	ctx, end := w.startOp(ctx, "go.cache.save", w.options.Save)
 	defer func() {
-		statsFunc(err)
+		end(ErrorResult(err))
 	}()
 
 	err = w.Cache.Save(wr)
@@ -891,53 +764,39 @@ func (w *Wrapper) Save(ctx context.Context, wr io.Writer) (err error) {
 
 // SaveFile implments pggocache savefile method with metrics
 func (w *Wrapper) SaveFile(ctx context.Context, fname string) (err error) {
-	if AllowTrace(ctx, w.options.SaveFile, w.options.AllowRoot) {
-		span := StartSpan(ctx, "go.cache.savefile", w.options)
-		if span != nil {
-			defer func() {
-				span.EndSpanWithErr(err)
-			}()
-		}
-	}
-	var statsFunc = recordCallErrorStatus(ctx, "go.cache.savefile", w.options.InstanceName)
+	ctx, end := w.startOp(ctx, "go.cache.savefile", w.options.SaveFile)
 	defer func() {
-		statsFunc(err)
+		end(ErrorResult(err))
 	}()
 
 	err = w.Cache.SaveFile(fname)
 	return
 }
 
-// Set implments pggocache set method with metrics
+// Set implments pggocache set method with metrics.
+// In write through mode the item is cached even if the Store fails, the failure is recorded on the span and by
+// MeasureStoreFailures. Use SetWithErr to only cache items the Store accepted.
 func (w *Wrapper) Set(ctx context.Context, k string, x interface{}, d time.Duration) {
-	if AllowTrace(ctx, w.options.Set, w.options.AllowRoot) {
-		span := StartSpan(ctx, "go.cache.set", w.options)
-		if span != nil {
-			defer func() {
-				span.EndSpan()
-			}()
-		}
-	}
-	var statsFunc = recordCallStats(ctx, "go.cache.set", w.options.InstanceName)
+	var err error
+	ctx, end := w.startKeyOp(ctx, "go.cache.set", w.options.Set, k, x)
 	defer func() {
-		statsFunc()
+		// the item is cached even if the Store failed
+		end(w.storedResult(writeResult(err), nil, d))
 	}()
 
-	w.Cache.Set(k, x, d)
+	k, d = w.key(k), w.jitter(d)
+	err = w.set(ctx, k, x, d)
 }
 
 // SetDefault implments pggocache setdefault method with metrics
 func (w *Wrapper) SetDefault(ctx context.Context, k string, x interface{}) {
-	if AllowTrace(ctx, w.options.SetDefault, w.options.AllowRoot) {
-		span := StartSpan(ctx, "go.cache.setdefault", w.options)
-		if span != nil {
-			defer span.EndSpan()
-		}
-	}
-	var statsFunc = recordCallStats(ctx, "go.cache.setdefault", w.options.InstanceName)
+	var err error
+	d := w.jitter(pgocache.DefaultExpiration)
+	ctx, end := w.startKeyOp(ctx, "go.cache.setdefault", w.options.SetDefault, k, x)
 	defer func() {
-		statsFunc()
+		end(w.storedResult(writeResult(err), nil, d))
 	}()
 
-	w.Cache.SetDefault(k, x)
+	k = w.key(k)
+	err = w.set(ctx, k, x, d)
 }
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"io/ioutil"
+	"reflect"
 	"runtime"
 	"strconv"
 	"sync"
@@ -1641,3 +1642,31 @@ func TestGetWithExpiration(t *testing.T) {
 		t.Error("expiration for e is in the past")
 	}
 }
+
+func TestWithAllTraceOptionsKeepsSettings(t *testing.T) {
+	store := newMemoryStore()
+	tc := Wrap(pgocache.New(pgocache.DefaultExpiration, 0),
+		WithMaxItems(1),
+		WithWriteThrough(store),
+		WithAllTraceOptions(),
+	)
+
+	tc.Set(context.Background(), "a", 1, pgocache.DefaultExpiration)
+	tc.Set(context.Background(), "b", 2, pgocache.DefaultExpiration)
+	if n := tc.ItemCount(context.Background()); n != 1 {
+		t.Error("expected MaxItems to be kept, got items:", n)
+	}
+	if _, ok := store.get("b"); !ok {
+		t.Error("expected the Store to be kept")
+	}
+
+	// every method enabled by AllTraceOptions must be set by WithAllTraceOptions
+	var o TraceOptions
+	WithAllTraceOptions()(&o)
+	all, got := reflect.ValueOf(AllTraceOptions), reflect.ValueOf(o)
+	for i := 0; i < all.NumField(); i++ {
+		if f := all.Field(i); f.Kind() == reflect.Bool && f.Bool() && !got.Field(i).Bool() {
+			t.Error("expected WithAllTraceOptions to set", all.Type().Field(i).Name)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// persistence selects whether a write reaches the Store
+type persistence int
+
+const (
+	// cacheOnly writes to the cache alone, for items read from the Store, loaded or known to be absent
+	cacheOnly persistence = iota
+
+	// persistAndCache writes to the Store and caches the item even if the Store fails
+	persistAndCache
+
+	// persistThenCache caches the item only once the Store accepted it
+	persistThenCache
+)
+
+// set persists and caches x under k, reporting any item it replaces, and returns the error of the Store. The item
+// is cached even if the Store fails.
+func (w *Wrapper) set(ctx context.Context, k string, x interface{}, d time.Duration) error {
+	return w.write(ctx, k, x, d, persistAndCache, nil)
+}
+
+// fill caches x under k without writing it to the Store
+func (w *Wrapper) fill(ctx context.Context, k string, x interface{}, d time.Duration) {
+	_ = w.write(ctx, k, x, d, cacheOnly, nil)
+}
+
+// write stores x under k according to p, calling with, if not nil, before the lock on k is released so that state
+// kept alongside the item is updated atomically with it. The Store is written under the lock on k so that it sees
+// the writes to a key in the same order as the cache.
+func (w *Wrapper) write(ctx context.Context, k string, x interface{}, d time.Duration, p persistence, with func()) (err error) {
+	unlock := w.locks.lock(k)
+	if p != cacheOnly {
+		if err = w.persist(ctx, k, x, d); err != nil && p == persistThenCache {
+			unlock()
+			return err
+		}
+	}
+	old, replaced := w.Cache.Get(k)
+	w.Cache.Set(k, x, w.expiration(d))
+	w.written(k, nil)
+	if with != nil {
+		with()
+	}
+	unlock()
+
+	w.stored(ctx, k, x, old, replaced)
+
+	return err
+}
+
+// add persists and caches x under k if it does not already exist, returning the error of the Store once cached. A key
+// known to be absent does not exist.
+func (w *Wrapper) add(ctx context.Context, k string, x interface{}, d time.Duration) error {
+	unlock := w.locks.lock(k)
+	old, found := w.Cache.Get(k)
+	var err error
+	if found && IsAbsent(old) {
+		w.Cache.Set(k, x, w.expiration(d))
+	} else {
+		err = w.Cache.Add(k, x, w.expiration(d))
+	}
+	w.written(k, err)
+	if err != nil {
+		unlock()
+		return err
+	}
+	err = w.persist(ctx, k, x, d)
+	unlock()
+
+	w.stored(ctx, k, x, old, found)
+
+	return err
+}
+
+// replace persists and caches x under k if it already exists, reporting the item it replaces, and returns the error
+// of the Store once cached. A key known to be absent does not exist.
+func (w *Wrapper) replace(ctx context.Context, k string, x interface{}, d time.Duration) error {
+	unlock := w.locks.lock(k)
+	old, _ := w.Cache.Get(k)
+	var err error
+	if IsAbsent(old) {
+		// as returned by go-cache for missing items
+		err = fmt.Errorf("Item %s doesn't exist", k)
+	} else {
+		err = w.Cache.Replace(k, x, w.expiration(d))
+	}
+	w.written(k, err)
+	if err != nil {
+		unlock()
+		return err
+	}
+	err = w.persist(ctx, k, x, d)
+	unlock()
+
+	w.stored(ctx, k, x, old, true)
+
+	return err
+}
+
+// remove deletes k from the Store and then from the cache, attributing the eviction to reason. It reports whether
+// an item was removed from the cache and returns the error of the Store. The Store is written, or the delete queued
+// when writing behind, first so that a read through following the removal does not cache the deleted item again.
+func (w *Wrapper) remove(ctx context.Context, k string, reason EvictionReason) (removed bool, err error) {
+	unlock := w.locks.lock(k)
+	err = w.unpersist(ctx, k)
+	unlock()
+
+	return w.delete(ctx, k, reason), err
+}
+
+// stored keeps the cache within capacity after x was stored under k and reports the item it replaced, if any.
+// It is called once the lock on k is released so that eviction listeners may write to the cache.
+// Keys known to be absent are not items, they are neither admitted nor reported.
+func (w *Wrapper) stored(ctx context.Context, k string, x interface{}, old interface{}, replaced bool) {
+	w.negatives.stored(k, x)
+	replaced = replaced && !IsAbsent(old)
+
+	if IsAbsent(x) {
+		if w.capacity != nil {
+			w.capacity.removed(k)
+		}
+		if replaced {
+			w.watchers.evicted(ctx, k, old, EvictionReasonDeleted)
+		}
+	} else {
+		w.watchers.written(ctx, k, x, replaced)
+		w.admit(ctx, k, x)
+	}
+
+	if replaced {
+		w.notifyEvicted(ctx, k, old, EvictionReasonReplaced)
+	}
+}
+
+// flush deletes all items from the cache, reporting each of them as flushed. The flush of a namespace only deletes
+// the items stored in it.
+func (w *Wrapper) flush(ctx context.Context) {
+	if w.prefix != "" {
+		for k := range w.Cache.Items() {
+			if strings.HasPrefix(k, w.prefix) {
+				w.delete(ctx, k, EvictionReasonFlushed)
+			}
+		}
+		return
+	}
+
+	items := w.Cache.Items()
+
+	w.Cache.Flush()
+	if w.capacity != nil {
+		w.capacity.reset()
+	}
+	w.versions.reset()
+	w.tags.reset()
+	w.sliding.reset()
+
+	w.negatives.reset()
+
+	for k, item := range items {
+		w.notifyEvicted(ctx, k, item.Object, EvictionReasonFlushed)
+	}
+}